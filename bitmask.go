@@ -0,0 +1,121 @@
+package pflag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// -- bitmask Value
+type bitmaskValue struct {
+	value *int
+	bits  map[string]int
+}
+
+func newBitmaskValue(bits map[string]int, p *int) *bitmaskValue {
+	return &bitmaskValue{value: p, bits: bits}
+}
+
+func (b *bitmaskValue) Set(s string) error {
+	mask := 0
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		bit, ok := b.bits[name]
+		if !ok {
+			return fmt.Errorf("unknown name %q, expected one of %s", name, strings.Join(b.names(), ", "))
+		}
+		mask |= bit
+	}
+	*b.value = mask
+	return nil
+}
+
+func (b *bitmaskValue) String() string {
+	var set []string
+	for name, bit := range b.bits {
+		if bit != 0 && *b.value&bit == bit {
+			set = append(set, name)
+		}
+	}
+	sort.Strings(set)
+	return strings.Join(set, ",")
+}
+
+func (b *bitmaskValue) Type() string { return "bitmask" }
+
+func (b *bitmaskValue) clone() Value {
+	v := *b.value
+	return &bitmaskValue{value: &v, bits: b.bits}
+}
+
+func (b *bitmaskValue) save() func() {
+	v := *b.value
+	return func() { *b.value = v }
+}
+
+func (b *bitmaskValue) Get() interface{} { return *b.value }
+
+// names returns all known names in sorted order.
+func (b *bitmaskValue) names() []string {
+	names := make([]string, 0, len(b.bits))
+	for name := range b.bits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BitmaskVar defines a bitmask flag with specified name, name-to-bit mapping, and usage string.
+// The flag accepts a comma-separated list of names from bits, and the argument p points to
+// an int variable in which to store the bitwise OR of the named bits. The default value is
+// the current value of *p.
+func (f *FlagSet) BitmaskVar(p *int, name string, bits map[string]int, usage string) {
+	f.VarP(newBitmaskValue(bits, p), name, "", usage)
+}
+
+// Like BitmaskVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) BitmaskVarP(p *int, name, shorthand string, bits map[string]int, usage string) {
+	f.VarP(newBitmaskValue(bits, p), name, shorthand, usage)
+}
+
+// BitmaskVar defines a bitmask flag with specified name, name-to-bit mapping, and usage string.
+// The flag accepts a comma-separated list of names from bits, and the argument p points to
+// an int variable in which to store the bitwise OR of the named bits. The default value is
+// the current value of *p.
+func BitmaskVar(p *int, name string, bits map[string]int, usage string) {
+	CommandLine.VarP(newBitmaskValue(bits, p), name, "", usage)
+}
+
+// Like BitmaskVar, but accepts a shorthand letter that can be used after a single dash.
+func BitmaskVarP(p *int, name, shorthand string, bits map[string]int, usage string) {
+	CommandLine.VarP(newBitmaskValue(bits, p), name, shorthand, usage)
+}
+
+// Bitmask defines a bitmask flag with specified name, name-to-bit mapping, and usage string.
+// The return value is the address of an int variable that stores the value of the flag.
+func (f *FlagSet) Bitmask(name string, bits map[string]int, usage string) *int {
+	p := new(int)
+	f.BitmaskVarP(p, name, "", bits, usage)
+	return p
+}
+
+// Like Bitmask, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) BitmaskP(name, shorthand string, bits map[string]int, usage string) *int {
+	p := new(int)
+	f.BitmaskVarP(p, name, shorthand, bits, usage)
+	return p
+}
+
+// Bitmask defines a bitmask flag with specified name, name-to-bit mapping, and usage string.
+// The return value is the address of an int variable that stores the value of the flag.
+func Bitmask(name string, bits map[string]int, usage string) *int {
+	return CommandLine.BitmaskP(name, "", bits, usage)
+}
+
+// Like Bitmask, but accepts a shorthand letter that can be used after a single dash.
+func BitmaskP(name, shorthand string, bits map[string]int, usage string) *int {
+	return CommandLine.BitmaskP(name, shorthand, bits, usage)
+}
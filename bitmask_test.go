@@ -0,0 +1,54 @@
+package pflag
+
+import "testing"
+
+var testCaps = map[string]int{
+	"read":  1,
+	"write": 2,
+	"exec":  4,
+}
+
+func setUpBitmaskFlagSet(caps *int) *FlagSet {
+	f := NewFlagSet("test", ContinueOnError)
+	f.BitmaskVarP(caps, "caps", "c", testCaps, "capabilities")
+	return f
+}
+
+func TestBitmaskMultiple(t *testing.T) {
+	var caps int
+	f := setUpBitmaskFlagSet(&caps)
+	if err := f.Parse([]string{"--caps=write,read"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if caps != 3 {
+		t.Fatal("expected caps 3 but got", caps)
+	}
+	if s := f.Lookup("caps").Value.String(); s != "read,write" {
+		t.Fatalf("expected %q but got %q", "read,write", s)
+	}
+}
+
+func TestBitmaskSingle(t *testing.T) {
+	var caps int
+	f := setUpBitmaskFlagSet(&caps)
+	if err := f.Parse([]string{"-c", "exec"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if caps != 4 {
+		t.Fatal("expected caps 4 but got", caps)
+	}
+	if s := f.Lookup("caps").Value.String(); s != "exec" {
+		t.Fatalf("expected %q but got %q", "exec", s)
+	}
+}
+
+func TestBitmaskUnknown(t *testing.T) {
+	var caps int
+	f := setUpBitmaskFlagSet(&caps)
+	if err := f.Parse([]string{"--caps=read,admin"}); err == nil {
+		t.Fatal("expected an error for unknown name")
+	}
+	if caps != 0 {
+		t.Fatal("expected caps to be unchanged but got", caps)
+	}
+}
@@ -22,12 +22,29 @@ func newBoolValue(val bool, p *bool) *boolValue {
 
 func (b *boolValue) Set(s string) error {
 	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
 	*b = boolValue(v)
-	return err
+	return nil
 }
 
 func (b *boolValue) String() string { return fmt.Sprintf("%v", *b) }
 
+func (b *boolValue) Type() string { return "bool" }
+
+func (b *boolValue) clone() Value {
+	v := *b
+	return &v
+}
+
+func (b *boolValue) save() func() {
+	v := *b
+	return func() { *b = v }
+}
+
+func (b *boolValue) Get() interface{} { return bool(*b) }
+
 func (b *boolValue) IsBoolFlag() bool { return true }
 
 // BoolVar defines a bool flag with specified name, default value, and usage string.
@@ -77,3 +94,62 @@ func Bool(name string, value bool, usage string) *bool {
 func BoolP(name, shorthand string, value bool, usage string) *bool {
 	return CommandLine.BoolP(name, shorthand, value, usage)
 }
+
+// GetBool returns the bool value of the named flag.
+func (f *FlagSet) GetBool(name string) (bool, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return false, err
+	}
+	tv, ok := v.(*boolValue)
+	if !ok {
+		return false, fmt.Errorf("flag -%v is not a bool flag", name)
+	}
+	return bool(*tv), nil
+}
+
+// SetBool sets the named bool flag to value and marks it as set, as Set
+// does, without formatting and parsing the value.
+func (f *FlagSet) SetBool(name string, value bool) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	tv, ok := flag.Value.(*boolValue)
+	if !ok {
+		return fmt.Errorf("flag -%v is not a bool flag", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	*tv = boolValue(value)
+	f.markSet(flag, SourceAPI)
+	return nil
+}
+
+// MarkNegatable makes the named bool flag also accept --no-<name>, which
+// sets it to false, as --<name>=false does. The negated form takes no
+// value and is not listed in the usage message. It is an error if a flag
+// named no-<name> is already defined; one defined later takes precedence.
+func (f *FlagSet) MarkNegatable(name string) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	if bv, ok := flag.Value.(boolFlag); !ok || !bv.IsBoolFlag() {
+		return fmt.Errorf("flag -%v is not a bool flag", name)
+	}
+	if _, taken := f.lookup("no-" + flag.Name); taken {
+		return fmt.Errorf("%s flag redefined: no-%s", f.name, flag.Name)
+	}
+	if f.negatable == nil {
+		f.negatable = make(map[*Flag]bool)
+	}
+	f.negatable[flag] = true
+	return nil
+}
+
+// MarkNegatable makes the named command-line bool flag also accept --no-<name>.
+func MarkNegatable(name string) error {
+	return CommandLine.MarkNegatable(name)
+}
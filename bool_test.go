@@ -7,7 +7,9 @@ package pflag
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -162,3 +164,74 @@ func TestInvalidValue(t *testing.T) {
 		t.Fatal("expected an error but did not get any, tristate has value", tristate)
 	}
 }
+
+func TestMarkNegatable(t *testing.T) {
+	newSet := func() (*FlagSet, *bool) {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		verbose := f.Bool("verbose", true, "")
+		if err := f.MarkNegatable("verbose"); err != nil {
+			t.Fatal("expected no error; got", err)
+		}
+		return f, verbose
+	}
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"--verbose"}, true},
+		{[]string{"--no-verbose"}, false},
+		{[]string{"--verbose=false"}, false},
+		{[]string{"--no-verbose", "--verbose"}, true},
+		{[]string{"--verbose", "--no-verbose"}, false},
+	}
+	for _, test := range tests {
+		f, verbose := newSet()
+		if err := f.Parse(test.args); err != nil {
+			t.Errorf("%v: expected no error; got %v", test.args, err)
+			continue
+		}
+		if *verbose != test.want || !f.Changed("verbose") {
+			t.Errorf("%v: expected verbose=%v and changed; got %v", test.args, test.want, *verbose)
+		}
+	}
+
+	f, _ := newSet()
+	if err := f.Parse([]string{"--no-verbose=true"}); err == nil {
+		t.Error("expected an error for a value given to --no-verbose")
+	}
+	if strings.Contains(f.FlagUsages(), "no-verbose") {
+		t.Error("expected --no-verbose to be left out of the usage message")
+	}
+	f = NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	f.Bool("color", false, "")
+	if err := f.Parse([]string{"--no-color"}); err == nil {
+		t.Error("expected --no-color to be unknown unless marked negatable")
+	}
+}
+
+func TestMarkNegatableCollision(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Bool("cache", true, "")
+	noCache := f.String("no-cache", "", "")
+	if err := f.MarkNegatable("cache"); err == nil {
+		t.Error("expected an error when no-cache is already defined")
+	}
+
+	f = NewFlagSet("test", ContinueOnError)
+	cache := f.Bool("cache", true, "")
+	if err := f.MarkNegatable("cache"); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	noCache = f.String("no-cache", "", "")
+	if err := f.Parse([]string{"--no-cache=x"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !*cache || *noCache != "x" {
+		t.Errorf("expected the explicit --no-cache flag to take precedence; got cache=%v no-cache=%q", *cache, *noCache)
+	}
+	if err := f.MarkNegatable("no-cache"); err == nil {
+		t.Error("expected an error marking a string flag negatable")
+	}
+}
@@ -0,0 +1,124 @@
+package pflag
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// -- *bool Value, where nil means unset
+type boolTristateValue struct {
+	value **bool
+}
+
+func newBoolTristateValue(p **bool) *boolTristateValue {
+	*p = nil
+	return &boolTristateValue{value: p}
+}
+
+func (b *boolTristateValue) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*b.value = &v
+	return nil
+}
+
+// String returns "true" or "false", or the empty string if the flag is unset.
+func (b *boolTristateValue) String() string {
+	if *b.value == nil {
+		return ""
+	}
+	return strconv.FormatBool(**b.value)
+}
+
+func (b *boolTristateValue) Type() string { return "boolTristate" }
+
+func (b *boolTristateValue) clone() Value {
+	v := *b.value
+	return &boolTristateValue{value: &v}
+}
+
+func (b *boolTristateValue) save() func() {
+	v := *b.value
+	return func() { *b.value = v }
+}
+
+func (b *boolTristateValue) Get() interface{} { return *b.value }
+
+func (b *boolTristateValue) IsBoolFlag() bool { return true }
+
+// reset is like Set, but also accepts the empty string for unset.
+func (b *boolTristateValue) reset(s string) error {
+	if s == "" {
+		*b.value = nil
+		return nil
+	}
+	return b.Set(s)
+}
+
+// BoolTristateVar defines a bool flag with specified name and usage string that
+// distinguishes being left unset from being set to false. The argument p points to
+// a *bool variable that is nil until the flag is set, and then points to its value.
+func (f *FlagSet) BoolTristateVar(p **bool, name string, usage string) {
+	f.VarP(newBoolTristateValue(p), name, "", usage)
+}
+
+// Like BoolTristateVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) BoolTristateVarP(p **bool, name, shorthand string, usage string) {
+	f.VarP(newBoolTristateValue(p), name, shorthand, usage)
+}
+
+// BoolTristateVar defines a bool flag with specified name and usage string that
+// distinguishes being left unset from being set to false. The argument p points to
+// a *bool variable that is nil until the flag is set, and then points to its value.
+func BoolTristateVar(p **bool, name string, usage string) {
+	CommandLine.VarP(newBoolTristateValue(p), name, "", usage)
+}
+
+// Like BoolTristateVar, but accepts a shorthand letter that can be used after a single dash.
+func BoolTristateVarP(p **bool, name, shorthand string, usage string) {
+	CommandLine.VarP(newBoolTristateValue(p), name, shorthand, usage)
+}
+
+// BoolTristate defines a bool flag with specified name and usage string that
+// distinguishes being left unset from being set to false. The return value is the
+// address of a *bool variable that is nil until the flag is set.
+func (f *FlagSet) BoolTristate(name string, usage string) **bool {
+	p := new(*bool)
+	f.BoolTristateVarP(p, name, "", usage)
+	return p
+}
+
+// Like BoolTristate, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) BoolTristateP(name, shorthand string, usage string) **bool {
+	p := new(*bool)
+	f.BoolTristateVarP(p, name, shorthand, usage)
+	return p
+}
+
+// BoolTristate defines a bool flag with specified name and usage string that
+// distinguishes being left unset from being set to false. The return value is the
+// address of a *bool variable that is nil until the flag is set.
+func BoolTristate(name string, usage string) **bool {
+	return CommandLine.BoolTristateP(name, "", usage)
+}
+
+// Like BoolTristate, but accepts a shorthand letter that can be used after a single dash.
+func BoolTristateP(name, shorthand string, usage string) **bool {
+	return CommandLine.BoolTristateP(name, shorthand, usage)
+}
+
+// GetBoolTristate returns the value of the named tri-state bool flag,
+// which is nil if the flag has not been set.
+func (f *FlagSet) GetBoolTristate(name string) (*bool, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return nil, err
+	}
+	tv, ok := v.(*boolTristateValue)
+	if !ok {
+		return nil, fmt.Errorf("flag -%v is not a tri-state bool flag", name)
+	}
+	return *tv.value, nil
+}
@@ -0,0 +1,53 @@
+package pflag
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestBoolTristate(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{}, "unset"},
+		{[]string{"--cache"}, "true"},
+		{[]string{"--cache=false"}, "false"},
+		{[]string{"-c"}, "true"},
+		{[]string{"--cache=true", "--cache=0"}, "false"},
+	}
+	for _, test := range tests {
+		f := NewFlagSet("test", ContinueOnError)
+		cache := f.BoolTristateP("cache", "c", "use the cache")
+		if err := f.Parse(test.args); err != nil {
+			t.Errorf("%v: expected no error; got %v", test.args, err)
+			continue
+		}
+		got := "unset"
+		if *cache != nil {
+			got = "false"
+			if **cache {
+				got = "true"
+			}
+		}
+		if got != test.expected {
+			t.Errorf("%v: expected %s but got %s", test.args, test.expected, got)
+		}
+		v, err := f.GetBoolTristate("cache")
+		if err != nil || v != *cache {
+			t.Errorf("%v: expected GetBoolTristate to return %v but got %v, %v", test.args, *cache, v, err)
+		}
+	}
+}
+
+func TestBoolTristateInvalid(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	cache := f.BoolTristate("cache", "use the cache")
+	if err := f.Parse([]string{"--cache=maybe"}); err == nil {
+		t.Fatal("expected an error for an invalid bool")
+	}
+	if *cache != nil {
+		t.Fatal("expected the flag to remain unset but got", **cache)
+	}
+}
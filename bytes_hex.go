@@ -0,0 +1,104 @@
+package pflag
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// -- []byte Value, hex-encoded
+type bytesHexValue []byte
+
+func newBytesHexValue(val []byte, p *[]byte) *bytesHexValue {
+	*p = val
+	return (*bytesHexValue)(p)
+}
+
+// Set decodes s as hexadecimal, in either case. An empty s gives an empty slice.
+func (b *bytesHexValue) Set(s string) error {
+	v, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+func (b *bytesHexValue) String() string { return strings.ToUpper(hex.EncodeToString(*b)) }
+
+func (b *bytesHexValue) Type() string { return "bytesHex" }
+
+func (b *bytesHexValue) clone() Value {
+	v := append(bytesHexValue(nil), *b...)
+	return &v
+}
+
+func (b *bytesHexValue) save() func() {
+	v := append(bytesHexValue(nil), *b...)
+	return func() { *b = append(bytesHexValue(nil), v...) }
+}
+
+func (b *bytesHexValue) Get() interface{} { return []byte(*b) }
+
+// BytesHexVar defines a []byte flag with specified name, default value, and usage string.
+// The flag's value is given in hexadecimal on the command line.
+// The argument p points to a []byte variable in which to store the value of the flag.
+func (f *FlagSet) BytesHexVar(p *[]byte, name string, value []byte, usage string) {
+	f.VarP(newBytesHexValue(value, p), name, "", usage)
+}
+
+// Like BytesHexVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) BytesHexVarP(p *[]byte, name, shorthand string, value []byte, usage string) {
+	f.VarP(newBytesHexValue(value, p), name, shorthand, usage)
+}
+
+// BytesHexVar defines a []byte flag with specified name, default value, and usage string.
+// The flag's value is given in hexadecimal on the command line.
+// The argument p points to a []byte variable in which to store the value of the flag.
+func BytesHexVar(p *[]byte, name string, value []byte, usage string) {
+	CommandLine.VarP(newBytesHexValue(value, p), name, "", usage)
+}
+
+// Like BytesHexVar, but accepts a shorthand letter that can be used after a single dash.
+func BytesHexVarP(p *[]byte, name, shorthand string, value []byte, usage string) {
+	CommandLine.VarP(newBytesHexValue(value, p), name, shorthand, usage)
+}
+
+// BytesHex defines a []byte flag with specified name, default value, and usage string.
+// The return value is the address of a []byte variable that stores the value of the flag.
+func (f *FlagSet) BytesHex(name string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	f.BytesHexVarP(p, name, "", value, usage)
+	return p
+}
+
+// Like BytesHex, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) BytesHexP(name, shorthand string, value []byte, usage string) *[]byte {
+	p := new([]byte)
+	f.BytesHexVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// BytesHex defines a []byte flag with specified name, default value, and usage string.
+// The return value is the address of a []byte variable that stores the value of the flag.
+func BytesHex(name string, value []byte, usage string) *[]byte {
+	return CommandLine.BytesHexP(name, "", value, usage)
+}
+
+// Like BytesHex, but accepts a shorthand letter that can be used after a single dash.
+func BytesHexP(name, shorthand string, value []byte, usage string) *[]byte {
+	return CommandLine.BytesHexP(name, shorthand, value, usage)
+}
+
+// GetBytesHex returns the []byte value of the named hex flag.
+func (f *FlagSet) GetBytesHex(name string) ([]byte, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return nil, err
+	}
+	tv, ok := v.(*bytesHexValue)
+	if !ok {
+		return nil, fmt.Errorf("flag -%v is not a hex bytes flag", name)
+	}
+	return []byte(*tv), nil
+}
@@ -0,0 +1,48 @@
+package pflag
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestBytesHex(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	key := f.BytesHexP("key", "k", []byte{0xff}, "key")
+	if s := f.Lookup("key").DefValue; s != "FF" {
+		t.Fatalf("expected default %q but got %q", "FF", s)
+	}
+	if err := f.Parse([]string{"--key=0a1B2c"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !bytes.Equal(*key, []byte{0x0a, 0x1b, 0x2c}) {
+		t.Fatalf("expected % x but got % x", []byte{0x0a, 0x1b, 0x2c}, *key)
+	}
+	if s := f.Lookup("key").Value.String(); s != "0A1B2C" {
+		t.Fatalf("expected %q but got %q", "0A1B2C", s)
+	}
+	if v, err := f.GetBytesHex("key"); err != nil || !bytes.Equal(v, *key) {
+		t.Fatalf("expected GetBytesHex to return % x but got % x, %v", *key, v, err)
+	}
+
+	if err := f.Parse([]string{"-k", ""}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if *key == nil || len(*key) != 0 {
+		t.Fatalf("expected an empty slice but got %#v", *key)
+	}
+}
+
+func TestBytesHexInvalid(t *testing.T) {
+	for _, arg := range []string{"abc", "zz", "0x10"} {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		key := f.BytesHex("key", []byte{1}, "key")
+		if err := f.Parse([]string{"--key=" + arg}); err == nil {
+			t.Errorf("%s: expected an error", arg)
+		}
+		if !bytes.Equal(*key, []byte{1}) {
+			t.Errorf("%s: expected the value to be unchanged but got % x", arg, *key)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package pflag
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// BashCompFilenameExt is the annotation key under which GenBashCompletion
+// looks for the file extensions a flag's value should be completed from.
+// An empty list completes any file name.
+const BashCompFilenameExt = "bash_completion_filename_extensions"
+
+// GenBashCompletion writes to w a bash completion script for the flags in
+// f, which can be sourced to complete them on the command line of the
+// program named by f. A name given as a path is reduced to its last
+// element, and a flag set with no name is an error. Every flag is offered
+// under its long name and shorthand except deprecated ones, which are also
+// left out of the usage message; a deprecated shorthand is likewise left
+// out. The value of a flag annotated with BashCompFilenameExt is completed
+// from file names.
+func (f *FlagSet) GenBashCompletion(w io.Writer) error {
+	if f.name == "" {
+		return fmt.Errorf("cannot generate bash completion for a flag set with no name")
+	}
+	name := filepath.Base(f.name)
+	fn := "_" + bashIdentifier(name) + "_completions"
+	var words []string
+	var files bytes.Buffer
+	f.VisitAll(func(flag *Flag) {
+		if len(flag.Deprecated) > 0 {
+			return
+		}
+		var names []string
+		if !f.shorthandOnly[flag] {
+			names = append(names, "--"+flag.Name)
+		}
+		if len(flag.Shorthand) > 0 && len(flag.ShorthandDeprecated) == 0 {
+			names = append(names, "-"+flag.Shorthand)
+		}
+		pattern := strings.Join(names, "|")
+		words = append(words, names...)
+		exts, ok := flag.Annotations[BashCompFilenameExt]
+		if !ok {
+			return
+		}
+		fmt.Fprintf(&files, "\t%s)\n", pattern)
+		if len(exts) > 0 {
+			fmt.Fprintf(&files, "\t\t_filedir '@(%s)'\n", strings.Join(exts, "|"))
+		} else {
+			fmt.Fprintf(&files, "\t\t_filedir\n")
+		}
+		fmt.Fprintf(&files, "\t\treturn\n\t\t;;\n")
+	})
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# bash completion for %s\n", strings.Replace(name, "\n", " ", -1))
+	fmt.Fprintf(&buf, "%s()\n{\n", fn)
+	fmt.Fprintf(&buf, "\tlocal cur prev\n")
+	fmt.Fprintf(&buf, "\tcur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	fmt.Fprintf(&buf, "\tprev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	if files.Len() > 0 {
+		fmt.Fprintf(&buf, "\tcase \"${prev}\" in\n%s\tesac\n", files.String())
+	}
+	fmt.Fprintf(&buf, "\tCOMPREPLY=( $(compgen -W \"%s\" -- \"${cur}\") )\n", strings.Join(words, " "))
+	fmt.Fprintf(&buf, "}\n")
+	fmt.Fprintf(&buf, "complete -F %s %s\n", fn, bashWord(name))
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// GenBashCompletion writes a bash completion script for the command-line flags to w.
+func GenBashCompletion(w io.Writer) error {
+	return CommandLine.GenBashCompletion(w)
+}
+
+// bashIdentifier replaces the characters of name that may not appear in a
+// bash function name with underscores.
+func bashIdentifier(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// bashWord quotes s for use as a single word in a bash command, if it
+// holds anything but letters, digits and the characters _ . + -.
+func bashWord(s string) string {
+	plain := strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '_' || r == '.' || r == '+' || r == '-' ||
+			r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) < 0
+	if plain {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
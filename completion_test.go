@@ -0,0 +1,86 @@
+package pflag
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenBashCompletion(t *testing.T) {
+	f := NewFlagSet("my-tool", ContinueOnError)
+	f.BoolP("verbose", "v", false, "")
+	f.String("name", "", "")
+	f.StringP("config", "c", "", "")
+	f.String("output", "", "")
+	f.String("old", "", "")
+	f.MarkDeprecated("old", "use --name")
+	f.SetAnnotation("config", BashCompFilenameExt, []string{"json", "yaml"})
+	f.SetAnnotation("output", BashCompFilenameExt, nil)
+
+	var buf bytes.Buffer
+	if err := f.GenBashCompletion(&buf); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	script := buf.String()
+	for _, want := range []string{
+		"_my_tool_completions()\n{\n",
+		`compgen -W "--config -c --name --output --verbose -v" -- "${cur}"`,
+		"\t--config|-c)\n\t\t_filedir '@(json|yaml)'\n\t\treturn\n",
+		"\t--output)\n\t\t_filedir\n\t\treturn\n",
+		"complete -F _my_tool_completions my-tool\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected script to contain %q; got:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "--old") {
+		t.Errorf("expected deprecated flag to be left out; got:\n%s", script)
+	}
+}
+
+func TestGenBashCompletionNoFiles(t *testing.T) {
+	f := NewFlagSet("tool", ContinueOnError)
+	f.Int("count", 0, "")
+	var buf bytes.Buffer
+	if err := f.GenBashCompletion(&buf); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if strings.Contains(buf.String(), "case") {
+		t.Errorf("expected no case statement without file flags; got:\n%s", buf.String())
+	}
+}
+
+func TestGenBashCompletionName(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   string
+		cmd  string
+	}{
+		{"./bin/tool", "_tool_completions", "tool"},
+		{"/usr/local/bin/my-tool", "_my_tool_completions", "my-tool"},
+		{"it's", "_it_s_completions", `'it'\''s'`},
+	}
+	for _, test := range tests {
+		f := NewFlagSet(test.name, ContinueOnError)
+		f.Bool("verbose", false, "")
+		var buf bytes.Buffer
+		if err := f.GenBashCompletion(&buf); err != nil {
+			t.Errorf("%q: expected no error; got %v", test.name, err)
+			continue
+		}
+		if want := "complete -F " + test.fn + " " + test.cmd + "\n"; !strings.HasSuffix(buf.String(), want) {
+			t.Errorf("%q: expected script to end with %q; got:\n%s", test.name, want, buf.String())
+		}
+		if want := test.fn + "()\n"; !strings.Contains(buf.String(), want) {
+			t.Errorf("%q: expected script to define %q; got:\n%s", test.name, want, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := NewFlagSet("", ContinueOnError).GenBashCompletion(&buf); err == nil {
+		t.Error("expected an error for a flag set with no name")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing to be written; got %q", buf.String())
+	}
+}
@@ -0,0 +1,97 @@
+package pflag
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// -- count Value
+type countValue int
+
+func newCountValue(val int, p *int) *countValue {
+	*p = val
+	return (*countValue)(p)
+}
+
+// Set increments the count when s is "+1", which is what the flag is set
+// to each time it appears without a value, and otherwise sets it to s.
+func (i *countValue) Set(s string) error {
+	if s == "+1" {
+		*i = *i + 1
+		return nil
+	}
+	v, err := strconv.ParseInt(s, 0, 0)
+	if err != nil {
+		return err
+	}
+	*i = countValue(v)
+	return nil
+}
+
+func (i *countValue) String() string { return fmt.Sprintf("%v", *i) }
+
+func (i *countValue) Type() string { return "count" }
+
+func (i *countValue) clone() Value {
+	v := *i
+	return &v
+}
+
+func (i *countValue) save() func() {
+	v := *i
+	return func() { *i = v }
+}
+
+func (i *countValue) Get() interface{} { return int(*i) }
+
+// CountVar defines a count flag with specified name and usage string.
+// Each appearance of the flag without a value increments the count, so
+// -vvv or -v -v -v yield 3, while --verbose=5 sets the count to 5.
+// The argument p points to an int variable in which to store the value of the flag.
+func (f *FlagSet) CountVar(p *int, name string, usage string) {
+	f.VarP(newCountValue(0, p), name, "", usage)
+}
+
+// Like CountVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) CountVarP(p *int, name, shorthand string, usage string) {
+	f.VarP(newCountValue(0, p), name, shorthand, usage)
+}
+
+// CountVar defines a count flag with specified name and usage string.
+// Each appearance of the flag without a value increments the count, so
+// -vvv or -v -v -v yield 3, while --verbose=5 sets the count to 5.
+// The argument p points to an int variable in which to store the value of the flag.
+func CountVar(p *int, name string, usage string) {
+	CommandLine.VarP(newCountValue(0, p), name, "", usage)
+}
+
+// Like CountVar, but accepts a shorthand letter that can be used after a single dash.
+func CountVarP(p *int, name, shorthand string, usage string) {
+	CommandLine.VarP(newCountValue(0, p), name, shorthand, usage)
+}
+
+// Count defines a count flag with specified name and usage string.
+// The return value is the address of an int variable that stores the value of the flag.
+func (f *FlagSet) Count(name string, usage string) *int {
+	p := new(int)
+	f.CountVarP(p, name, "", usage)
+	return p
+}
+
+// Like Count, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) CountP(name, shorthand string, usage string) *int {
+	p := new(int)
+	f.CountVarP(p, name, shorthand, usage)
+	return p
+}
+
+// Count defines a count flag with specified name and usage string.
+// The return value is the address of an int variable that stores the value of the flag.
+func Count(name string, usage string) *int {
+	return CommandLine.CountP(name, "", usage)
+}
+
+// Like Count, but accepts a shorthand letter that can be used after a single dash.
+func CountP(name, shorthand string, usage string) *int {
+	return CommandLine.CountP(name, shorthand, usage)
+}
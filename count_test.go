@@ -0,0 +1,41 @@
+package pflag
+
+import "testing"
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{}, 0},
+		{[]string{"-v", "-v", "-v"}, 3},
+		{[]string{"-vvv"}, 3},
+		{[]string{"-vv", "--verbose"}, 3},
+		{[]string{"--verbose=5"}, 5},
+		{[]string{"--verbose=5", "-v"}, 6},
+		{[]string{"-qvq"}, 1},
+	}
+	for _, tt := range tests {
+		f := NewFlagSet("test", ContinueOnError)
+		verbose := f.CountP("verbose", "v", "verbosity")
+		f.BoolP("quiet", "q", false, "quiet")
+		if err := f.Parse(tt.args); err != nil {
+			t.Errorf("%q: expected no error; got %v", tt.args, err)
+			continue
+		}
+		if *verbose != tt.expected {
+			t.Errorf("%q: expected count %d but got %d", tt.args, tt.expected, *verbose)
+		}
+	}
+}
+
+func TestCountDoesNotConsumeArgument(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	verbose := f.CountP("verbose", "v", "verbosity")
+	if err := f.Parse([]string{"--verbose", "file"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if *verbose != 1 || f.NArg() != 1 || f.Arg(0) != "file" {
+		t.Fatalf("expected count 1 and args [file], got %d %v", *verbose, f.Args())
+	}
+}
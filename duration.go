@@ -1,6 +1,9 @@
 package pflag
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // -- time.Duration Value
 type durationValue time.Duration
@@ -12,12 +15,29 @@ func newDurationValue(val time.Duration, p *time.Duration) *durationValue {
 
 func (d *durationValue) Set(s string) error {
 	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
 	*d = durationValue(v)
-	return err
+	return nil
 }
 
 func (d *durationValue) String() string { return (*time.Duration)(d).String() }
 
+func (d *durationValue) Type() string { return "duration" }
+
+func (d *durationValue) clone() Value {
+	v := *d
+	return &v
+}
+
+func (d *durationValue) save() func() {
+	v := *d
+	return func() { *d = v }
+}
+
+func (d *durationValue) Get() interface{} { return time.Duration(*d) }
+
 // Value is the interface to the dynamic value stored in a flag.
 // (The default value is represented as a string.)
 type Value interface {
@@ -25,6 +45,38 @@ type Value interface {
 	Set(string) error
 }
 
+// Getter is an interface that allows the contents of a Value to be retrieved.
+// It wraps the Value interface, rather than being part of it, because it
+// appeared after Go 1 and its compatibility rules. All Value types provided
+// by this package satisfy the Getter interface.
+type Getter interface {
+	Value
+	Get() interface{}
+}
+
+// TypedValue is implemented by Values that can name their type, such as
+// "int" or "stringSlice", for Type and for the value placeholder in the
+// usage message. It wraps the Value interface, rather than being part of
+// it, so that existing Values still satisfy Value. All Value types
+// provided by this package satisfy the TypedValue interface.
+type TypedValue interface {
+	Value
+	Type() string
+}
+
+// SliceValue is implemented by Values that hold a list of elements, such
+// as the StringSlice and IntSlice flags. The first time such a flag is set
+// its default elements are discarded, so that the value given replaces the
+// default rather than being appended to it; later uses append.
+type SliceValue interface {
+	// Append parses val as a single element and adds it to the list.
+	Append(val string) error
+	// Replace parses vals and makes them the whole list.
+	Replace(vals []string) error
+	// GetSlice returns the elements formatted as strings.
+	GetSlice() []string
+}
+
 // DurationVar defines a time.Duration flag with specified name, default value, and usage string.
 // The argument p points to a time.Duration variable in which to store the value of the flag.
 func (f *FlagSet) DurationVar(p *time.Duration, name string, value time.Duration, usage string) {
@@ -72,3 +124,60 @@ func Duration(name string, value time.Duration, usage string) *time.Duration {
 func DurationP(name, shorthand string, value time.Duration, usage string) *time.Duration {
 	return CommandLine.DurationP(name, shorthand, value, usage)
 }
+
+// MarkNonNegative makes the named duration flag reject negative values,
+// both on the command line and through Set.
+func (f *FlagSet) MarkNonNegative(name string) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	if _, ok := flag.Value.(*durationValue); !ok {
+		return fmt.Errorf("flag -%v is not a duration flag", name)
+	}
+	if f.nonNegative == nil {
+		f.nonNegative = make(map[*Flag]bool)
+	}
+	f.nonNegative[flag] = true
+	return nil
+}
+
+// MarkNonNegative makes the named command-line duration flag reject negative values.
+func MarkNonNegative(name string) error {
+	return CommandLine.MarkNonNegative(name)
+}
+
+// GetDuration returns the time.Duration value of the named flag.
+func (f *FlagSet) GetDuration(name string) (time.Duration, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return 0, err
+	}
+	tv, ok := v.(*durationValue)
+	if !ok {
+		return 0, fmt.Errorf("flag -%v is not a duration flag", name)
+	}
+	return time.Duration(*tv), nil
+}
+
+// SetDuration sets the named time.Duration flag to value and marks it as set, as Set
+// does, without formatting and parsing the value.
+func (f *FlagSet) SetDuration(name string, value time.Duration) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	tv, ok := flag.Value.(*durationValue)
+	if !ok {
+		return fmt.Errorf("flag -%v is not a duration flag", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	if f.nonNegative[flag] && value < 0 {
+		return fmt.Errorf("negative duration not allowed")
+	}
+	*tv = durationValue(value)
+	f.markSet(flag, SourceAPI)
+	return nil
+}
@@ -0,0 +1,173 @@
+package pflag
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// -- []time.Duration Value
+type durationSliceValue []time.Duration
+
+func newDurationSliceValue(val []time.Duration, p *[]time.Duration) *durationSliceValue {
+	*p = val
+	return (*durationSliceValue)(p)
+}
+
+// Set parses the comma-separated elements of val with time.ParseDuration and
+// appends them to the slice. If any element fails to parse, none of them are appended.
+func (s *durationSliceValue) Set(val string) error {
+	if val == "" {
+		return nil
+	}
+	parts := strings.Split(val, ",")
+	out := make(durationSliceValue, 0, len(parts))
+	for _, part := range parts {
+		v, err := s.parseElem(part)
+		if err != nil {
+			return err
+		}
+		out = append(out, v)
+	}
+	*s = append(*s, out...)
+	return nil
+}
+
+func (s *durationSliceValue) String() string {
+	parts := make([]string, len(*s))
+	for i, v := range *s {
+		parts[i] = v.String()
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+func (s *durationSliceValue) Type() string { return "durationSlice" }
+
+func (s *durationSliceValue) clone() Value {
+	v := append(durationSliceValue(nil), *s...)
+	return &v
+}
+
+func (s *durationSliceValue) save() func() {
+	v := append(durationSliceValue(nil), *s...)
+	return func() { *s = append(durationSliceValue(nil), v...) }
+}
+
+func (s *durationSliceValue) Get() interface{} { return []time.Duration(*s) }
+
+// parseElem parses a single element of the slice.
+func (s *durationSliceValue) parseElem(val string) (time.Duration, error) {
+	v, err := time.ParseDuration(strings.TrimSpace(val))
+	if err != nil {
+		return 0, fmt.Errorf("invalid element %q: %v", val, err)
+	}
+	return v, nil
+}
+
+// Append parses val as a single element and adds it to the slice.
+func (s *durationSliceValue) Append(val string) error {
+	v, err := s.parseElem(val)
+	if err != nil {
+		return err
+	}
+	*s = append(*s, v)
+	return nil
+}
+
+// Replace sets the slice to the parsed vals. If any fails to parse, the
+// slice is unchanged.
+func (s *durationSliceValue) Replace(vals []string) error {
+	out := make(durationSliceValue, 0, len(vals))
+	for _, val := range vals {
+		v, err := s.parseElem(val)
+		if err != nil {
+			return err
+		}
+		out = append(out, v)
+	}
+	*s = out
+	return nil
+}
+
+// GetSlice returns the elements formatted as strings.
+func (s *durationSliceValue) GetSlice() []string {
+	out := make([]string, len(*s))
+	for i, v := range *s {
+		out[i] = v.String()
+	}
+	return out
+}
+
+func (s *durationSliceValue) restore(val string) error {
+	var v durationSliceValue
+	if err := v.Set(strings.TrimSuffix(strings.TrimPrefix(val, "["), "]")); err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// DurationSliceVar defines a []time.Duration flag with specified name, default value, and usage string.
+// Each use of the flag appends its comma-separated elements to the slice;
+// the first use replaces the default.
+// The argument p points to a []time.Duration variable in which to store the value of the flag.
+func (f *FlagSet) DurationSliceVar(p *[]time.Duration, name string, value []time.Duration, usage string) {
+	f.VarP(newDurationSliceValue(value, p), name, "", usage)
+}
+
+// Like DurationSliceVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) DurationSliceVarP(p *[]time.Duration, name, shorthand string, value []time.Duration, usage string) {
+	f.VarP(newDurationSliceValue(value, p), name, shorthand, usage)
+}
+
+// DurationSliceVar defines a []time.Duration flag with specified name, default value, and usage string.
+// Each use of the flag appends its comma-separated elements to the slice;
+// the first use replaces the default.
+// The argument p points to a []time.Duration variable in which to store the value of the flag.
+func DurationSliceVar(p *[]time.Duration, name string, value []time.Duration, usage string) {
+	CommandLine.VarP(newDurationSliceValue(value, p), name, "", usage)
+}
+
+// Like DurationSliceVar, but accepts a shorthand letter that can be used after a single dash.
+func DurationSliceVarP(p *[]time.Duration, name, shorthand string, value []time.Duration, usage string) {
+	CommandLine.VarP(newDurationSliceValue(value, p), name, shorthand, usage)
+}
+
+// DurationSlice defines a []time.Duration flag with specified name, default value, and usage string.
+// The return value is the address of a []time.Duration variable that stores the value of the flag.
+func (f *FlagSet) DurationSlice(name string, value []time.Duration, usage string) *[]time.Duration {
+	p := new([]time.Duration)
+	f.DurationSliceVarP(p, name, "", value, usage)
+	return p
+}
+
+// Like DurationSlice, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) DurationSliceP(name, shorthand string, value []time.Duration, usage string) *[]time.Duration {
+	p := new([]time.Duration)
+	f.DurationSliceVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// DurationSlice defines a []time.Duration flag with specified name, default value, and usage string.
+// The return value is the address of a []time.Duration variable that stores the value of the flag.
+func DurationSlice(name string, value []time.Duration, usage string) *[]time.Duration {
+	return CommandLine.DurationSliceP(name, "", value, usage)
+}
+
+// Like DurationSlice, but accepts a shorthand letter that can be used after a single dash.
+func DurationSliceP(name, shorthand string, value []time.Duration, usage string) *[]time.Duration {
+	return CommandLine.DurationSliceP(name, shorthand, value, usage)
+}
+
+// GetDurationSlice returns a copy of the []time.Duration value of the named flag.
+func (f *FlagSet) GetDurationSlice(name string) ([]time.Duration, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return nil, err
+	}
+	sv, ok := v.(*durationSliceValue)
+	if !ok {
+		return nil, fmt.Errorf("flag -%v is not a duration slice flag", name)
+	}
+	return append([]time.Duration(nil), *sv...), nil
+}
@@ -0,0 +1,57 @@
+package pflag
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDurationSlice(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	timeouts := f.DurationSliceP("timeouts", "t", nil, "timeouts")
+	if err := f.Parse([]string{"--timeouts", "1s,2m,500ms", "-t", "90s"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	expected := []time.Duration{time.Second, 2 * time.Minute, 500 * time.Millisecond, 90 * time.Second}
+	if !reflect.DeepEqual(*timeouts, expected) {
+		t.Fatalf("expected %v but got %v", expected, *timeouts)
+	}
+	if s := f.Lookup("timeouts").Value.String(); s != "[1s,2m0s,500ms,1m30s]" {
+		t.Fatalf("expected %q but got %q", "[1s,2m0s,500ms,1m30s]", s)
+	}
+}
+
+func TestDurationSliceInvalid(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	timeouts := f.DurationSlice("timeouts", nil, "timeouts")
+	err := f.Parse([]string{"--timeouts=1s,1x"})
+	if err == nil || !strings.Contains(err.Error(), `"1x"`) {
+		t.Fatal("expected an error naming the bad element; got", err)
+	}
+	if len(*timeouts) != 0 {
+		t.Fatal("expected nothing appended on error but got", *timeouts)
+	}
+}
+
+func TestGetDurationSlice(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	backoff := f.DurationSlice("backoff", nil, "")
+	f.Int("count", 0, "")
+	if err := f.Parse([]string{"--backoff=1s,2s"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	got, err := f.GetDurationSlice("backoff")
+	if err != nil || !reflect.DeepEqual(got, []time.Duration{time.Second, 2 * time.Second}) {
+		t.Fatalf("expected [1s 2s]; got %v, %v", got, err)
+	}
+	got[0] = 0
+	if (*backoff)[0] != time.Second {
+		t.Error("expected the returned slice to be a copy; flag now holds", *backoff)
+	}
+	if _, err := f.GetDurationSlice("count"); err == nil {
+		t.Error("expected an error getting an int flag as a duration slice")
+	}
+}
@@ -0,0 +1,200 @@
+package pflag
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// An Endpoint is a network address given as host:port.
+type Endpoint struct {
+	Host string
+	Port int
+}
+
+func (e Endpoint) String() string {
+	return net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+}
+
+// parseEndpoint parses a host:port pair, requiring a port in 1-65535.
+func parseEndpoint(s string) (Endpoint, error) {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		return Endpoint{}, err
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("invalid port in %q", s)
+	}
+	if p < 1 || p > 65535 {
+		return Endpoint{}, fmt.Errorf("port %d in %q out of range 1-65535", p, s)
+	}
+	return Endpoint{Host: host, Port: p}, nil
+}
+
+// -- []Endpoint Value
+type endpointSliceValue []Endpoint
+
+func newEndpointSliceValue(val []Endpoint, p *[]Endpoint) *endpointSliceValue {
+	*p = val
+	return (*endpointSliceValue)(p)
+}
+
+// Set parses the comma-separated host:port elements of val and appends
+// them to the slice. If any element fails to parse, none of them are appended.
+func (s *endpointSliceValue) Set(val string) error {
+	if val == "" {
+		return nil
+	}
+	parts := strings.Split(val, ",")
+	out := make(endpointSliceValue, 0, len(parts))
+	for _, part := range parts {
+		v, err := s.parseElem(part)
+		if err != nil {
+			return err
+		}
+		out = append(out, v)
+	}
+	*s = append(*s, out...)
+	return nil
+}
+
+func (s *endpointSliceValue) String() string {
+	parts := make([]string, len(*s))
+	for i, e := range *s {
+		parts[i] = e.String()
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+func (s *endpointSliceValue) Type() string { return "endpointSlice" }
+
+func (s *endpointSliceValue) clone() Value {
+	v := append(endpointSliceValue(nil), *s...)
+	return &v
+}
+
+func (s *endpointSliceValue) save() func() {
+	v := append(endpointSliceValue(nil), *s...)
+	return func() { *s = append(endpointSliceValue(nil), v...) }
+}
+
+func (s *endpointSliceValue) Get() interface{} { return []Endpoint(*s) }
+
+// parseElem parses a single element of the slice.
+func (s *endpointSliceValue) parseElem(val string) (Endpoint, error) {
+	v, err := parseEndpoint(strings.TrimSpace(val))
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("invalid element %q: %v", val, err)
+	}
+	return v, nil
+}
+
+// Append parses val as a single element and adds it to the slice.
+func (s *endpointSliceValue) Append(val string) error {
+	v, err := s.parseElem(val)
+	if err != nil {
+		return err
+	}
+	*s = append(*s, v)
+	return nil
+}
+
+// Replace sets the slice to the parsed vals. If any fails to parse, the
+// slice is unchanged.
+func (s *endpointSliceValue) Replace(vals []string) error {
+	out := make(endpointSliceValue, 0, len(vals))
+	for _, val := range vals {
+		v, err := s.parseElem(val)
+		if err != nil {
+			return err
+		}
+		out = append(out, v)
+	}
+	*s = out
+	return nil
+}
+
+// GetSlice returns the elements formatted as strings.
+func (s *endpointSliceValue) GetSlice() []string {
+	out := make([]string, len(*s))
+	for i, v := range *s {
+		out[i] = v.String()
+	}
+	return out
+}
+
+func (s *endpointSliceValue) restore(val string) error {
+	var v endpointSliceValue
+	if err := v.Set(strings.TrimSuffix(strings.TrimPrefix(val, "["), "]")); err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// EndpointSliceVar defines a []Endpoint flag with specified name, default value, and usage string.
+// Each use of the flag appends its comma-separated host:port elements to the slice;
+// the first use replaces the default.
+// The argument p points to a []Endpoint variable in which to store the value of the flag.
+func (f *FlagSet) EndpointSliceVar(p *[]Endpoint, name string, value []Endpoint, usage string) {
+	f.VarP(newEndpointSliceValue(value, p), name, "", usage)
+}
+
+// Like EndpointSliceVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) EndpointSliceVarP(p *[]Endpoint, name, shorthand string, value []Endpoint, usage string) {
+	f.VarP(newEndpointSliceValue(value, p), name, shorthand, usage)
+}
+
+// EndpointSliceVar defines a []Endpoint flag with specified name, default value, and usage string.
+// Each use of the flag appends its comma-separated host:port elements to the slice;
+// the first use replaces the default.
+// The argument p points to a []Endpoint variable in which to store the value of the flag.
+func EndpointSliceVar(p *[]Endpoint, name string, value []Endpoint, usage string) {
+	CommandLine.VarP(newEndpointSliceValue(value, p), name, "", usage)
+}
+
+// Like EndpointSliceVar, but accepts a shorthand letter that can be used after a single dash.
+func EndpointSliceVarP(p *[]Endpoint, name, shorthand string, value []Endpoint, usage string) {
+	CommandLine.VarP(newEndpointSliceValue(value, p), name, shorthand, usage)
+}
+
+// EndpointSlice defines a []Endpoint flag with specified name, default value, and usage string.
+// The return value is the address of a []Endpoint variable that stores the value of the flag.
+func (f *FlagSet) EndpointSlice(name string, value []Endpoint, usage string) *[]Endpoint {
+	p := new([]Endpoint)
+	f.EndpointSliceVarP(p, name, "", value, usage)
+	return p
+}
+
+// Like EndpointSlice, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) EndpointSliceP(name, shorthand string, value []Endpoint, usage string) *[]Endpoint {
+	p := new([]Endpoint)
+	f.EndpointSliceVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// EndpointSlice defines a []Endpoint flag with specified name, default value, and usage string.
+// The return value is the address of a []Endpoint variable that stores the value of the flag.
+func EndpointSlice(name string, value []Endpoint, usage string) *[]Endpoint {
+	return CommandLine.EndpointSliceP(name, "", value, usage)
+}
+
+// Like EndpointSlice, but accepts a shorthand letter that can be used after a single dash.
+func EndpointSliceP(name, shorthand string, value []Endpoint, usage string) *[]Endpoint {
+	return CommandLine.EndpointSliceP(name, shorthand, value, usage)
+}
+
+// GetEndpointSlice returns a copy of the []Endpoint value of the named flag.
+func (f *FlagSet) GetEndpointSlice(name string) ([]Endpoint, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return nil, err
+	}
+	sv, ok := v.(*endpointSliceValue)
+	if !ok {
+		return nil, fmt.Errorf("flag -%v is not an endpoint slice flag", name)
+	}
+	return append([]Endpoint(nil), *sv...), nil
+}
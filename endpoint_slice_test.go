@@ -0,0 +1,50 @@
+package pflag
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEndpointSlice(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.EndpointSliceP("endpoints", "e", nil, "brokers")
+	args := []string{"--endpoints=a:9092,b:9092", "-e", "[::1]:9093"}
+	if err := f.Parse(args); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	endpoints, err := f.GetEndpointSlice("endpoints")
+	if err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	expected := []Endpoint{{"a", 9092}, {"b", 9092}, {"::1", 9093}}
+	if !reflect.DeepEqual(endpoints, expected) {
+		t.Fatalf("expected %v but got %v", expected, endpoints)
+	}
+	if s := f.Lookup("endpoints").Value.String(); s != "[a:9092,b:9092,[::1]:9093]" {
+		t.Fatalf("expected %q but got %q", "[a:9092,b:9092,[::1]:9093]", s)
+	}
+}
+
+func TestEndpointSliceInvalid(t *testing.T) {
+	for _, arg := range []string{"a:9092,b", "a:0", "a:65536", "a:http"} {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		endpoints := f.EndpointSlice("endpoints", nil, "brokers")
+		err := f.Parse([]string{"--endpoints=" + arg})
+		if err == nil {
+			t.Errorf("expected an error for %q", arg)
+			continue
+		}
+		if len(*endpoints) != 0 {
+			t.Errorf("expected nothing appended for %q but got %v", arg, *endpoints)
+		}
+	}
+
+	f := NewFlagSet("test", ContinueOnError)
+	f.Int("port", 0, "")
+	if _, err := f.GetEndpointSlice("port"); err == nil || !strings.Contains(err.Error(), "endpoint") {
+		t.Error("expected an error getting an int flag as endpoints; got", err)
+	}
+}
@@ -11,14 +11,18 @@ import (
 
 // Additional routines compiled into the package only during testing.
 
-// ResetForTesting clears all flag state and sets the usage function as directed.
+// commandLineUsage is the default Usage, saved before tests replace it.
+var commandLineUsage = Usage
+
+// ResetForTesting clears all flag state and sets the usage function as directed,
+// or back to the default if usage is nil.
 // After calling ResetForTesting, parse errors in flag handling will not
 // exit the program.
 func ResetForTesting(usage func()) {
-	CommandLine = &FlagSet{
-		name:          os.Args[0],
-		errorHandling: ContinueOnError,
-		output:        ioutil.Discard,
+	CommandLine = NewFlagSet(os.Args[0], ContinueOnError)
+	CommandLine.SetOutput(ioutil.Discard)
+	if usage == nil {
+		usage = commandLineUsage
 	}
 	Usage = usage
 }
@@ -99,17 +99,66 @@
 package pflag
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // ErrHelp is the error returned if the flag -help is invoked but no such flag is defined.
 var ErrHelp = errors.New("pflag: help requested")
 
+// ParseErrorKind classifies a ParseError.
+type ParseErrorKind int
+
+const (
+	UnknownFlag   ParseErrorKind = iota + 1 // no flag has the given name
+	MissingValue                            // the flag needs a value and none followed it
+	InvalidValue                            // the flag's Value rejected the given value
+	BadSyntax                               // the argument is not a well-formed flag
+	AmbiguousFlag                           // a prefix matches several flags; see AllowPrefixMatch
+	DuplicateFlag                           // the flag was repeated; see DisallowDuplicates
+)
+
+var parseErrorKindNames = []string{
+	UnknownFlag:   "unknown flag",
+	MissingValue:  "missing value",
+	InvalidValue:  "invalid value",
+	BadSyntax:     "bad syntax",
+	AmbiguousFlag: "ambiguous flag",
+	DuplicateFlag: "duplicate flag",
+}
+
+func (k ParseErrorKind) String() string {
+	if k <= 0 || int(k) >= len(parseErrorKindNames) {
+		return fmt.Sprintf("ParseErrorKind(%d)", int(k))
+	}
+	return parseErrorKindNames[k]
+}
+
+// A ParseError is returned by Parse for an argument it cannot handle, so
+// that callers can tell the failures apart with errors.As.
+type ParseError struct {
+	Kind  ParseErrorKind
+	Flag  string // the flag name or shorthand, without dashes, if known
+	Token string // the argument in which the error was found
+	Err   error  // the error from the flag's Value, for InvalidValue
+
+	msg string
+}
+
+func (e *ParseError) Error() string { return e.msg }
+
+// Unwrap returns the error from the flag's Value, if any.
+func (e *ParseError) Unwrap() error { return e.Err }
+
 // ErrorHandling defines how to handle flag parsing errors.
 type ErrorHandling int
 
@@ -126,16 +175,63 @@ type FlagSet struct {
 	// a custom error handler.
 	Usage func()
 
+	// SortFlags controls the order in which VisitAll and the usage
+	// message list flags: lexicographical if true, and the order they were
+	// defined in otherwise. NewFlagSet and Init set it to true; it is false
+	// in a zero FlagSet that has not been initialized.
+	SortFlags bool
+
+	// AllowPrefixMatch lets Parse accept an unambiguous prefix of a long
+	// flag name, so that --verb sets --verbose if no other flag begins with
+	// "verb". A prefix shared by several flags is an error.
+	AllowPrefixMatch bool
+
+	// DisallowDuplicates makes it an error for a flag to appear more than
+	// once in the arguments to Parse, rather than the last value winning.
+	// Slice, map and count flags, which accumulate their values, may still
+	// be repeated.
+	DisallowDuplicates bool
+
 	name          string
 	parsed        bool
 	actual        map[string]*Flag
 	formal        map[string]*Flag
+	ordered       []*Flag // formal flags in the order they were defined
 	shorthands    map[byte]*Flag
 	args          []string // arguments after flags
+	argsLenAtDash int      // len(args) when "--" was seen, or -1
 	exitOnError   bool     // does the program exit if there's an error?
 	errorHandling ErrorHandling
 	output        io.Writer // nil means stderr; use out() accessor
 	interspersed  bool      // allow interspersed option/non-option args
+	rawTail       bool      // keep everything from the first positional or unknown flag as args
+	fileMaxDepth  int       // nesting limit for @file expansion; 0 means the default
+	fileMaxBytes  int64     // size limit for each expanded file; 0 means the default
+
+	caseInsensitive map[string]*Flag   // flags matched ignoring case, keyed by lower-cased name
+	aliases         map[string]*Flag   // flags reachable under another name, keyed by alias
+	nonNegative     map[*Flag]bool     // duration flags that reject negative values
+	negatable       map[*Flag]bool     // bool flags that also accept --no-name
+	shorthandOnly   map[*Flag]bool     // flags with no long name, keyed by their shorthand
+	positionals     map[int]*Flag      // flags filled from positional arguments, by index
+	expandEnv       bool               // expand $VAR references in values before setting
+	expandFiles     bool               // replace @file arguments with the file's contents
+	passthrough     map[*Flag]*FlagSet // flag sets that parse the arguments after a flag
+	greedyMaps      map[*Flag]bool     // map flags that consume following key=value arguments
+	redefinePolicy  RedefinePolicy     // how to handle a clashing definition
+	requiredOneOf   [][]*Flag          // groups of which at least one flag must be set
+	exclusive       [][]*Flag          // groups of which at most one flag may be set
+	required        map[*Flag]bool     // flags that must be set
+	envVars         map[*Flag]string   // environment variables consulted for unset flags
+	commandLineOnly map[*Flag]bool     // flags that ignore environment and config values
+	immutable       map[*Flag]bool     // flags that may be set only once; true once they have been
+	defaults        map[*Flag]func()   // restore the values flags were defined with
+	strictJSON      bool               // reject unknown keys in ApplyJSON
+
+	normalizeNameFunc func(f *FlagSet, name string) NormalizedName
+	changes           chan<- FlagChange // notified of each successful set
+	warned            map[string]bool   // deprecated --names and -shorthands whose warning has been printed
+	given             map[*Flag]bool    // flags given in the arguments to the current Parse
 }
 
 // A Flag represents the state of a flag.
@@ -145,6 +241,39 @@ type Flag struct {
 	Usage     string // help message
 	Value     Value  // value as set
 	DefValue  string // default value (as text); for usage message
+	Source    Source // where the current value came from
+
+	Deprecated          string // if non-empty, the flag is deprecated and this explains what to use instead
+	ShorthandDeprecated string // if non-empty, the shorthand is deprecated and this explains what to use instead
+	NoOptDefVal         string // if non-empty, the value used when the flag is given without one
+
+	Annotations map[string][]string // free-form data for completion and other tools; see SetAnnotation
+}
+
+// Source identifies where a flag's current value came from.
+type Source int
+
+const (
+	SourceDefault     Source = iota // the flag has its default value
+	SourceCommandLine               // set while parsing arguments
+	SourceEnv                       // set from a bound environment variable
+	SourceConfig                    // set from a configuration file
+	SourceAPI                       // set by a call to Set
+)
+
+var sourceNames = []string{
+	SourceDefault:     "default",
+	SourceCommandLine: "command line",
+	SourceEnv:         "environment",
+	SourceConfig:      "config",
+	SourceAPI:         "api",
+}
+
+func (s Source) String() string {
+	if s < 0 || int(s) >= len(sourceNames) {
+		return fmt.Sprintf("Source(%d)", int(s))
+	}
+	return sourceNames[s]
 }
 
 // sortFlags returns the flags as a slice in lexicographical sorted order.
@@ -176,20 +305,350 @@ func (f *FlagSet) SetOutput(output io.Writer) {
 	f.output = output
 }
 
-// VisitAll visits the flags in lexicographical order, calling fn for each.
-// It visits all flags, even those not set.
+// VisitAll visits the flags in lexicographical order, or in the order they
+// were defined if SortFlags is false, calling fn for each. It visits all
+// flags, even those not set.
 func (f *FlagSet) VisitAll(fn func(*Flag)) {
-	for _, flag := range sortFlags(f.formal) {
-		fn(flag)
+	if f.SortFlags {
+		f.VisitAllSorted(fn)
+	} else {
+		f.VisitAllInOrder(fn)
 	}
 }
 
-// VisitAll visits the command-line flags in lexicographical order, calling
+// VisitAll visits the command-line flags in lexicographical order, or in
+// the order they were defined if CommandLine.SortFlags is false, calling
 // fn for each.  It visits all flags, even those not set.
 func VisitAll(fn func(*Flag)) {
 	CommandLine.VisitAll(fn)
 }
 
+// VisitAllSorted visits the flags in lexicographical order, calling fn
+// for each. It visits all flags, even those not set.
+func (f *FlagSet) VisitAllSorted(fn func(*Flag)) {
+	for _, flag := range sortFlags(f.formal) {
+		fn(flag)
+	}
+}
+
+// VisitAllSorted visits the command-line flags in lexicographical order,
+// calling fn for each. It visits all flags, even those not set.
+func VisitAllSorted(fn func(*Flag)) {
+	CommandLine.VisitAllSorted(fn)
+}
+
+// VisitAllInOrder visits the flags in the order they were defined,
+// calling fn for each. It visits all flags, even those not set.
+func (f *FlagSet) VisitAllInOrder(fn func(*Flag)) {
+	for _, flag := range f.ordered {
+		fn(flag)
+	}
+}
+
+// VisitAllInOrder visits the command-line flags in the order they were
+// defined, calling fn for each. It visits all flags, even those not set.
+func VisitAllInOrder(fn func(*Flag)) {
+	CommandLine.VisitAllInOrder(fn)
+}
+
+// Flags returns all defined flags in lexicographical order. A flag with
+// only a shorthand is listed with its shorthand as Name; ShorthandOnly
+// tells such flags apart from long flags with a one-letter name.
+func (f *FlagSet) Flags() []*Flag {
+	return sortFlags(f.formal)
+}
+
+// Flags returns all defined command-line flags in lexicographical order.
+func Flags() []*Flag {
+	return CommandLine.Flags()
+}
+
+// DefinedNames returns the long names of all defined flags in
+// lexicographical order. Flags with only a shorthand have no long name and
+// are left out; their letters are returned by ShorthandNames. The slice is
+// newly allocated on each call.
+func (f *FlagSet) DefinedNames() []string {
+	names := make([]string, 0, len(f.formal))
+	for name, flag := range f.formal {
+		if !f.shorthandOnly[flag] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefinedNames returns the names of all defined command-line flags in lexicographical order.
+func DefinedNames() []string {
+	return CommandLine.DefinedNames()
+}
+
+// ShorthandNames returns the shorthand letters of all defined flags, each
+// as a one-character string, in lexicographical order. The slice is newly
+// allocated on each call.
+func (f *FlagSet) ShorthandNames() []string {
+	names := make([]string, 0, len(f.shorthands))
+	for c := range f.shorthands {
+		names = append(names, string(c))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ShorthandNames returns the shorthand letters of all defined command-line flags in lexicographical order.
+func ShorthandNames() []string {
+	return CommandLine.ShorthandNames()
+}
+
+// ShorthandOnly reports whether the named flag was defined with only a
+// shorthand, so that its Name is that shorthand rather than a long name.
+// It returns false for undefined flags.
+func (f *FlagSet) ShorthandOnly(name string) bool {
+	flag, ok := f.lookup(name)
+	return ok && f.shorthandOnly[flag]
+}
+
+// ShorthandOnly reports whether the named command-line flag was defined with only a shorthand.
+func ShorthandOnly(name string) bool {
+	return CommandLine.ShorthandOnly(name)
+}
+
+// DiffDefaults compares the default values of the flags in f against those
+// of the same-named flags in baseline. It returns, for each flag whose
+// default differs, its name mapped to [default in f, default in baseline].
+// Flags defined in only one of the two sets are ignored.
+func (f *FlagSet) DiffDefaults(baseline *FlagSet) map[string][2]string {
+	diff := make(map[string][2]string)
+	for name, flag := range f.formal {
+		other, ok := baseline.formal[name]
+		if !ok || other.DefValue == flag.DefValue {
+			continue
+		}
+		diff[name] = [2]string{flag.DefValue, other.DefValue}
+	}
+	return diff
+}
+
+// Snapshot returns the current value of every flag in the set, keyed by
+// flag name, for later use with Restore. It also records where each value
+// came from and whether the flag has been set, under the flag's name with
+// a dash in front: "-count" holds "set from command line" if --count was
+// given, or the name of its Source, such as "default", if it was not.
+func (f *FlagSet) Snapshot() map[string]string {
+	snap := make(map[string]string, 2*len(f.formal))
+	for name, flag := range f.formal {
+		snap[name] = flag.Value.String()
+		state := flag.Source.String()
+		if _, set := f.actual[name]; set {
+			state = setStatePrefix + state
+		}
+		snap["-"+name] = state
+	}
+	return snap
+}
+
+// setStatePrefix marks the state Snapshot records for a flag that has
+// been set.
+const setStatePrefix = "set from "
+
+// parseSetState parses a state recorded by Snapshot.
+func parseSetState(state string) (source Source, set bool, err error) {
+	name := strings.TrimPrefix(state, setStatePrefix)
+	for s, n := range sourceNames {
+		if n == name {
+			return Source(s), name != state, nil
+		}
+	}
+	return SourceDefault, false, fmt.Errorf("unknown flag state %q", state)
+}
+
+// restorer is implemented by values whose Set accumulates, so that Restore
+// can replace their contents instead of adding to them.
+type restorer interface {
+	restore(s string) error
+}
+
+// resetter is implemented by values that print a form Set does not
+// accept, such as the empty string for an unset tri-state bool, so that
+// Restore can return them to it.
+type resetter interface {
+	reset(s string) error
+}
+
+// restoreValue sets v back to s, a value it printed earlier, without
+// adding to it if its Set accumulates.
+func restoreValue(v Value, s string) error {
+	switch r := v.(type) {
+	case restorer:
+		return r.restore(s)
+	case resetter:
+		return r.reset(s)
+	}
+	return v.Set(s)
+}
+
+// cloner is implemented by the values defined in this package, so that
+// Validate can parse into copies of them.
+type cloner interface {
+	clone() Value
+}
+
+// cloneValue returns a copy of v that can be set without changing v, and
+// whether v could be copied.
+func cloneValue(v Value) (Value, bool) {
+	switch v := v.(type) {
+	case multiValue:
+		m := make(multiValue, len(v))
+		for i, value := range v {
+			var ok bool
+			if m[i], ok = cloneValue(value); !ok {
+				return nil, false
+			}
+		}
+		return m, true
+	case cloner:
+		return v.clone(), true
+	}
+	return nil, false
+}
+
+// saver is implemented by the values defined in this package. save
+// returns a function that puts the value back exactly as it is now, which
+// ResetFlag uses to return a flag to the value it was defined with.
+type saver interface {
+	save() func()
+}
+
+// saveValue returns the function that v's save returns, and whether v
+// could be saved.
+func saveValue(v Value) (func(), bool) {
+	switch v := v.(type) {
+	case multiValue:
+		restores := make([]func(), len(v))
+		for i, value := range v {
+			var ok bool
+			if restores[i], ok = saveValue(value); !ok {
+				return nil, false
+			}
+		}
+		return func() {
+			for _, restore := range restores {
+				restore()
+			}
+		}, true
+	case saver:
+		return v.save(), true
+	}
+	return nil, false
+}
+
+// saveDefault records the value of flag, if it can be saved and is the
+// flag's default, for ResetFlag to return to.
+func (f *FlagSet) saveDefault(flag *Flag) {
+	delete(f.defaults, flag)
+	restore, ok := saveValue(flag.Value)
+	if !ok || flag.Value.String() != flag.DefValue {
+		return
+	}
+	if f.defaults == nil {
+		f.defaults = make(map[*Flag]func())
+	}
+	f.defaults[flag] = restore
+}
+
+// uncheckedValue stands in during Validate for a Value that cannot be
+// copied. It accepts anything and reports the last value it was given.
+type uncheckedValue struct {
+	value    string
+	typeName string
+	boolFlag bool
+}
+
+func (u *uncheckedValue) Set(s string) error {
+	u.value = s
+	return nil
+}
+
+func (u *uncheckedValue) String() string { return u.value }
+
+func (u *uncheckedValue) Type() string { return u.typeName }
+
+func (u *uncheckedValue) IsBoolFlag() bool { return u.boolFlag }
+
+// validationValue returns the Value that Validate sets in place of v.
+func validationValue(v Value) Value {
+	if c, ok := cloneValue(v); ok {
+		return c
+	}
+	u := &uncheckedValue{value: v.String(), typeName: valueType(v)}
+	if b, ok := v.(boolFlag); ok {
+		u.boolFlag = b.IsBoolFlag()
+	}
+	return u
+}
+
+// Restore sets each flag named in snap back to its recorded value. Flags
+// whose value is unchanged are left alone; the others are reset without
+// being marked as set, so Restore is an undo rather than a new assignment.
+// Where snap also records a flag's state, as Snapshot does, the flag's
+// Source and whether it counts as set, as reported by Changed, are
+// restored too. It returns an error if snap names an unknown flag, would
+// change an immutable flag that has been set, or holds a value or state
+// the flag rejects, in which case the flags before it have been restored.
+func (f *FlagSet) Restore(snap map[string]string) error {
+	names := make([]string, 0, len(snap))
+	for name := range snap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.HasPrefix(name, "-") {
+			continue
+		}
+		flag, ok := f.formal[string(f.normalizeFlagName(name))]
+		if !ok {
+			return fmt.Errorf("no such flag -%v", name)
+		}
+		value := snap[name]
+		if flag.Value.String() == value {
+			continue
+		}
+		if err := f.checkMutable(flag); err != nil {
+			return err
+		}
+		if err := restoreValue(flag.Value, value); err != nil {
+			return fmt.Errorf("invalid value %q for flag -%v: %v", value, name, err)
+		}
+	}
+	for _, key := range names {
+		if !strings.HasPrefix(key, "-") {
+			continue
+		}
+		name := key[1:]
+		flag, ok := f.formal[string(f.normalizeFlagName(name))]
+		if !ok {
+			return fmt.Errorf("no such flag -%v", name)
+		}
+		source, set, err := parseSetState(snap[key])
+		if err != nil {
+			return fmt.Errorf("invalid state for flag -%v: %v", name, err)
+		}
+		if !set {
+			if err := f.checkMutable(flag); err != nil {
+				return err
+			}
+			delete(f.actual, flag.Name)
+		} else {
+			if f.actual == nil {
+				f.actual = make(map[string]*Flag)
+			}
+			f.actual[flag.Name] = flag
+		}
+		flag.Source = source
+	}
+	return nil
+}
+
 // Visit visits the flags in lexicographical order, calling fn for each.
 // It visits only those flags that have been set.
 func (f *FlagSet) Visit(fn func(*Flag)) {
@@ -204,39 +663,575 @@ func Visit(fn func(*Flag)) {
 	CommandLine.Visit(fn)
 }
 
+// lookup finds the named flag, falling back to aliases and then to flags
+// that have been marked case-insensitive when there is no exact match.
+func (f *FlagSet) lookup(name string) (*Flag, bool) {
+	key := string(f.normalizeFlagName(name))
+	if flag, ok := f.formal[key]; ok {
+		return flag, true
+	}
+	if flag, ok := f.aliases[key]; ok {
+		return flag, true
+	}
+	flag, ok := f.caseInsensitive[strings.ToLower(key)]
+	return flag, ok
+}
+
+// prefixMatch returns the flag whose long name or alias begins with
+// prefix, or nil if there is none. It fails if prefix matches more than
+// one flag.
+func (f *FlagSet) prefixMatch(prefix string) (*Flag, error) {
+	key := string(f.normalizeFlagName(prefix))
+	matches := make(map[*Flag]bool)
+	var names []string
+	for _, m := range []map[string]*Flag{f.formal, f.aliases} {
+		for name, flag := range m {
+			if strings.HasPrefix(name, key) && !f.shorthandOnly[flag] {
+				matches[flag] = true
+				names = append(names, "--"+name)
+			}
+		}
+	}
+	if len(matches) > 1 {
+		sort.Strings(names)
+		return nil, f.failp(AmbiguousFlag, prefix, "--"+prefix, nil, "ambiguous flag: --%s could be %s", prefix, strings.Join(names, ", "))
+	}
+	for flag := range matches {
+		return flag, nil
+	}
+	return nil, nil
+}
+
+// AddAlias makes alias another long name for the flag named canonical, so
+// that --alias sets the same Value. The flag is still reported, for example
+// by Changed and in the usage message, under its canonical name only.
+func (f *FlagSet) AddAlias(canonical, alias string) error {
+	flag, ok := f.lookup(canonical)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", canonical)
+	}
+	key := string(f.normalizeFlagName(alias))
+	if _, taken := f.formal[key]; taken {
+		return fmt.Errorf("%s alias %s is already a flag", f.name, alias)
+	}
+	if other, taken := f.aliases[key]; taken && other != flag {
+		return fmt.Errorf("%s alias %s is already used for %s", f.name, alias, other.Name)
+	}
+	if f.aliases == nil {
+		f.aliases = make(map[string]*Flag)
+	}
+	f.aliases[key] = flag
+	return nil
+}
+
+// AddAlias makes alias another long name for the command-line flag named canonical.
+func AddAlias(canonical, alias string) error {
+	return CommandLine.AddAlias(canonical, alias)
+}
+
+// NormalizedName is a flag name that has been through the flag set's
+// normalization function.
+type NormalizedName string
+
+// SetNormalizeFunc installs a function that normalizes flag names, both
+// when flags are defined and when names are looked up, so that for example
+// --my-flag and --my_flag can be made to refer to the same flag, or an old
+// spelling can be kept working after a rename. Flags are stored under their
+// normalized names, and flags that are already defined are renamed.
+func (f *FlagSet) SetNormalizeFunc(n func(f *FlagSet, name string) NormalizedName) {
+	f.normalizeNameFunc = n
+	formal := make(map[string]*Flag, len(f.formal))
+	actual := make(map[string]*Flag, len(f.actual))
+	for _, flag := range f.formal {
+		_, set := f.actual[flag.Name]
+		flag.Name = string(f.normalizeFlagName(flag.Name))
+		formal[flag.Name] = flag
+		if set {
+			actual[flag.Name] = flag
+		}
+	}
+	f.formal = formal
+	f.actual = actual
+	aliases := make(map[string]*Flag, len(f.aliases))
+	for alias, flag := range f.aliases {
+		aliases[string(f.normalizeFlagName(alias))] = flag
+	}
+	f.aliases = aliases
+	for key, flag := range f.caseInsensitive {
+		delete(f.caseInsensitive, key)
+		f.caseInsensitive[strings.ToLower(flag.Name)] = flag
+	}
+}
+
+// GetNormalizeFunc returns the function that normalizes flag names, which
+// leaves names unchanged if none has been set.
+func (f *FlagSet) GetNormalizeFunc() func(f *FlagSet, name string) NormalizedName {
+	if f.normalizeNameFunc != nil {
+		return f.normalizeNameFunc
+	}
+	return func(f *FlagSet, name string) NormalizedName { return NormalizedName(name) }
+}
+
+func (f *FlagSet) normalizeFlagName(name string) NormalizedName {
+	return f.GetNormalizeFunc()(f, name)
+}
+
+// SetNormalizeFunc installs a function that normalizes command-line flag names.
+func SetNormalizeFunc(n func(f *FlagSet, name string) NormalizedName) {
+	CommandLine.SetNormalizeFunc(n)
+}
+
+// flagValue returns the Value of the named flag.
+func (f *FlagSet) flagValue(name string) (Value, error) {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("no such flag -%v", name)
+	}
+	return flag.Value, nil
+}
+
+// Get returns the typed value of the named flag, as reported by its
+// Value's Get method: a bool for a Bool flag, a []string for a StringSlice
+// flag and so on. It is an error if the Value does not implement Getter.
+func (f *FlagSet) Get(name string) (interface{}, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return nil, err
+	}
+	g, ok := v.(Getter)
+	if !ok {
+		return nil, fmt.Errorf("flag -%v does not implement Getter", name)
+	}
+	return g.Get(), nil
+}
+
+// Get returns the typed value of the named command-line flag.
+func Get(name string) (interface{}, error) {
+	return CommandLine.Get(name)
+}
+
+// Type returns the name of the type of the named flag's value, such as
+// "bool", "int", "stringSlice" or "duration", so that tools can tell, for
+// example, whether the flag consumes an argument. It is the name reported
+// by the Value's Type method; see TypedValue. Built-in values are named
+// after the function that defines them, except that enum and range flags
+// report the type they hold. A Value that does not implement TypedValue
+// is "value".
+func (f *FlagSet) Type(name string) (string, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return "", err
+	}
+	return valueType(v), nil
+}
+
+// Type returns the name of the type of the named command-line flag's value.
+func Type(name string) (string, error) {
+	return CommandLine.Type(name)
+}
+
+// valueType returns the type name of v; see Type.
+func valueType(v Value) string {
+	if tv, ok := v.(TypedValue); ok {
+		return tv.Type()
+	}
+	return "value"
+}
+
 // Lookup returns the Flag structure of the named flag, returning nil if none exists.
 func (f *FlagSet) Lookup(name string) *Flag {
-	return f.formal[name]
+	flag, _ := f.lookup(name)
+	return flag
 }
 
 // Lookup returns the Flag structure of the named command-line flag,
 // returning nil if none exists.
 func Lookup(name string) *Flag {
-	return CommandLine.formal[name]
+	return CommandLine.Lookup(name)
+}
+
+// MarkCaseInsensitive makes the named flag match on the command line and
+// in Lookup regardless of case, so --Verbose and --VERBOSE both find a flag
+// named "verbose". Other flags remain case-sensitive. It is an error if
+// another flag's name differs from this one only by case.
+func (f *FlagSet) MarkCaseInsensitive(name string) error {
+	flag, ok := f.formal[string(f.normalizeFlagName(name))]
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	key := strings.ToLower(flag.Name)
+	for _, other := range f.formal {
+		if other != flag && strings.ToLower(other.Name) == key {
+			return fmt.Errorf("flag %s differs only by case from %s", name, other.Name)
+		}
+	}
+	if f.caseInsensitive == nil {
+		f.caseInsensitive = make(map[string]*Flag)
+	}
+	f.caseInsensitive[key] = flag
+	return nil
+}
+
+// MarkCaseInsensitive makes the named command-line flag match regardless of case.
+func MarkCaseInsensitive(name string) error {
+	return CommandLine.MarkCaseInsensitive(name)
 }
 
 // Set sets the value of the named flag.
 func (f *FlagSet) Set(name, value string) error {
-	flag, ok := f.formal[name]
+	flag, ok := f.lookup(name)
 	if !ok {
 		return fmt.Errorf("no such flag -%v", name)
 	}
-	err := flag.Value.Set(value)
-	if err != nil {
+	return f.setFrom(flag, value, SourceAPI)
+}
+
+// setFrom sets flag to value, recording source as where it came from. It
+// is the one path through which Parse, Set, and the environment and config
+// sources set flags, so validation, the set state and change notifications
+// are the same for all of them. Environment and config values for
+// command-line-only flags are ignored.
+func (f *FlagSet) setFrom(flag *Flag, value string, source Source) error {
+	if f.commandLineOnly[flag] && (source == SourceEnv || source == SourceConfig) {
+		return nil
+	}
+	if err := f.assign(flag, value, source); err != nil {
 		return err
 	}
+	f.markSet(flag, source)
+	return nil
+}
+
+// markSet records that flag has been set from source and sends the change
+// notification.
+func (f *FlagSet) markSet(flag *Flag, source Source) {
+	flag.Source = source
+	f.freeze(flag)
 	if f.actual == nil {
 		f.actual = make(map[string]*Flag)
 	}
-	f.actual[name] = flag
+	f.actual[flag.Name] = flag
+	f.notify(flag)
+}
+
+// prime sets flag to value from a config source without marking it as
+// set: Changed stays false, the flag does not satisfy MarkRequired or
+// MarkImmutable, and a later set replaces the value of a slice flag rather
+// than adding to it. Config values for command-line-only flags are
+// ignored.
+func (f *FlagSet) prime(flag *Flag, value string) error {
+	if f.commandLineOnly[flag] {
+		return nil
+	}
+	if err := f.assign(flag, value, SourceConfig); err != nil {
+		return err
+	}
+	f.notify(flag)
+	return nil
+}
+
+// notify sends the change notification for flag.
+func (f *FlagSet) notify(flag *Flag) {
+	if f.changes != nil {
+		select {
+		case f.changes <- FlagChange{Name: flag.Name, Value: flag.Value.String()}:
+		default:
+		}
+	}
+}
+
+// assign validates value and sets flag to it, recording source, but does
+// not mark the flag as set.
+func (f *FlagSet) assign(flag *Flag, value string, source Source) error {
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	if f.expandEnv {
+		value = os.ExpandEnv(value)
+	}
+	if err := f.checkValue(flag, value); err != nil {
+		return err
+	}
+	if err := f.setValue(flag, value); err != nil {
+		return err
+	}
+	flag.Source = source
+	return nil
+}
+
+// setValue calls flag.Value.Set, first clearing the default of a slice
+// flag that has not been set yet.
+func (f *FlagSet) setValue(flag *Flag, value string) error {
+	sv, ok := flag.Value.(SliceValue)
+	if _, set := f.actual[flag.Name]; !ok || set {
+		return flag.Value.Set(value)
+	}
+	defaults := sv.GetSlice()
+	if err := sv.Replace(nil); err != nil {
+		return err
+	}
+	if err := flag.Value.Set(value); err != nil {
+		sv.Replace(defaults)
+		return err
+	}
+	return nil
+}
+
+// A FlagChange describes a flag being set.
+type FlagChange struct {
+	Name  string // the flag's name
+	Value string // the flag's value after it was set
+}
+
+// SetChangeChannel arranges for a FlagChange to be sent on ch each time a
+// flag is successfully set, whether on the command line or otherwise,
+// including values applied from config by ApplyJSON and ParseDir.
+// Sends never block: if ch is not ready the change is dropped, so ch
+// should normally be buffered. A nil ch turns notifications off.
+func (f *FlagSet) SetChangeChannel(ch chan<- FlagChange) {
+	f.changes = ch
+}
+
+// checkValue applies the constraints the flag set places on a flag
+// before the value is handed to the flag's Value.
+func (f *FlagSet) checkValue(flag *Flag, value string) error {
+	if f.nonNegative[flag] {
+		if d, err := time.ParseDuration(value); err == nil && d < 0 {
+			return fmt.Errorf("negative duration not allowed")
+		}
+	}
+	return nil
+}
+
+// MarkDeprecated marks the named flag as deprecated. The flag keeps
+// working, but it is left out of the usage message, and the first time it
+// is used on the command line a warning is printed that ends with
+// usageMessage, such as "use --new instead".
+func (f *FlagSet) MarkDeprecated(name string, usageMessage string) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	if len(usageMessage) == 0 {
+		return fmt.Errorf("deprecated message for flag -%v must be set", name)
+	}
+	flag.Deprecated = usageMessage
+	return nil
+}
+
+// MarkDeprecated marks the named command-line flag as deprecated.
+func MarkDeprecated(name string, usageMessage string) error {
+	return CommandLine.MarkDeprecated(name, usageMessage)
+}
+
+// MarkShorthandDeprecated marks the shorthand of the named flag as
+// deprecated. The flag keeps working under both names, but the usage
+// message lists it by its long name only, and the first time the
+// shorthand is used on the command line a warning is printed that ends
+// with usageMessage.
+func (f *FlagSet) MarkShorthandDeprecated(name string, usageMessage string) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	if len(usageMessage) == 0 {
+		return fmt.Errorf("deprecated message for flag -%v must be set", name)
+	}
+	if len(flag.Shorthand) == 0 || f.shorthandOnly[flag] {
+		return fmt.Errorf("flag -%v has no shorthand to deprecate", name)
+	}
+	flag.ShorthandDeprecated = usageMessage
 	return nil
 }
 
+// MarkShorthandDeprecated marks the shorthand of the named command-line flag as deprecated.
+func MarkShorthandDeprecated(name string, usageMessage string) error {
+	return CommandLine.MarkShorthandDeprecated(name, usageMessage)
+}
+
+// Changed reports whether the named flag was set, either on the command
+// line or through Set, even if it was set to its default or empty value.
+func (f *FlagSet) Changed(name string) bool {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return false
+	}
+	_, set := f.actual[flag.Name]
+	return set
+}
+
+// Changed reports whether the named command-line flag was set.
+func Changed(name string) bool {
+	return CommandLine.Changed(name)
+}
+
+// Source returns where the current value of the named flag came from.
+func (f *FlagSet) Source(name string) (Source, error) {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return SourceDefault, fmt.Errorf("no such flag -%v", name)
+	}
+	return flag.Source, nil
+}
+
 // Set sets the value of the named command-line flag.
 func Set(name, value string) error {
 	return CommandLine.Set(name, value)
 }
 
+// EffectiveString returns the value the named flag resolves to: the value
+// given on the command line (or via Set) if there was one, then the value
+// of its bound environment variable if that is set, then a value applied
+// from config by ApplyJSON or ParseDir, and otherwise the flag's default.
+func (f *FlagSet) EffectiveString(name string) (string, error) {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return "", fmt.Errorf("no such flag -%v", name)
+	}
+	if _, set := f.actual[flag.Name]; set {
+		return flag.Value.String(), nil
+	}
+	if envVar, bound := f.envVars[flag]; bound && !f.commandLineOnly[flag] {
+		if value, ok := os.LookupEnv(envVar); ok {
+			return value, nil
+		}
+	}
+	if flag.Source == SourceConfig {
+		return flag.Value.String(), nil
+	}
+	return flag.DefValue, nil
+}
+
+// BindEnv makes the named flag take its value from the environment
+// variable envVar when Parse finds that the flag was not set on the
+// command line. An invalid value in the environment is reported by Parse.
+func (f *FlagSet) BindEnv(name, envVar string) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	if f.envVars == nil {
+		f.envVars = make(map[*Flag]string)
+	}
+	f.envVars[flag] = envVar
+	return nil
+}
+
+// BindEnv makes the named command-line flag fall back to the environment variable envVar.
+func BindEnv(name, envVar string) error {
+	return CommandLine.BindEnv(name, envVar)
+}
+
+// MarkCommandLineOnly makes the named flag ignore values from the
+// environment and from configuration sources such as ParseDir, so that it
+// can only be set on the command line or through Set.
+func (f *FlagSet) MarkCommandLineOnly(name string) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	if f.commandLineOnly == nil {
+		f.commandLineOnly = make(map[*Flag]bool)
+	}
+	f.commandLineOnly[flag] = true
+	return nil
+}
+
+// MarkCommandLineOnly makes the named command-line flag ignore environment and configuration values.
+func MarkCommandLineOnly(name string) error {
+	return CommandLine.MarkCommandLineOnly(name)
+}
+
+// MarkImmutable makes the named flag settable only once. After its first
+// successful set, from the command line, the environment or the API, any
+// further attempt to set it, or to reset it with ResetFlag or Restore, is
+// an error, so a later layer of configuration cannot override it. Values
+// applied from config by ApplyJSON and ParseDir do not count as a set. A flag that has
+// already been set when it is marked can no longer be set at all.
+func (f *FlagSet) MarkImmutable(name string) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	if f.immutable == nil {
+		f.immutable = make(map[*Flag]bool)
+	}
+	_, set := f.actual[flag.Name]
+	f.immutable[flag] = set
+	return nil
+}
+
+// MarkImmutable makes the named command-line flag settable only once.
+func MarkImmutable(name string) error {
+	return CommandLine.MarkImmutable(name)
+}
+
+// checkMutable returns an error if flag is immutable and has been set.
+func (f *FlagSet) checkMutable(flag *Flag) error {
+	if f.immutable[flag] {
+		return fmt.Errorf("flag -%v is immutable and has already been set", flag.Name)
+	}
+	return nil
+}
+
+// freeze records that flag has been set, if it is immutable.
+func (f *FlagSet) freeze(flag *Flag) {
+	if _, ok := f.immutable[flag]; ok {
+		f.immutable[flag] = true
+	}
+}
+
+// applyEnv sets each unset flag that is bound to an environment variable
+// from that variable, if it is set.
+func (f *FlagSet) applyEnv() error {
+	bound := make(map[string]*Flag, len(f.envVars))
+	for flag := range f.envVars {
+		bound[flag.Name] = flag
+	}
+	for _, flag := range sortFlags(bound) {
+		if _, set := f.actual[flag.Name]; set {
+			continue
+		}
+		envVar := f.envVars[flag]
+		value, ok := os.LookupEnv(envVar)
+		if !ok {
+			continue
+		}
+		if err := f.setFrom(flag, value, SourceEnv); err != nil {
+			return f.failf("invalid value %q in $%s for flag --%s: %v", value, envVar, flag.Name, err)
+		}
+	}
+	return nil
+}
+
+// EffectiveBool is like EffectiveString, but parses the result as a bool.
+func (f *FlagSet) EffectiveBool(name string) (bool, error) {
+	s, err := f.EffectiveString(name)
+	if err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(s)
+}
+
+// EffectiveInt is like EffectiveString, but parses the result as an int.
+func (f *FlagSet) EffectiveInt(name string) (int, error) {
+	s, err := f.EffectiveString(name)
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(s, 0, 0)
+	return int(v), err
+}
+
+// EffectiveDuration is like EffectiveString, but parses the result as a time.Duration.
+func (f *FlagSet) EffectiveDuration(name string) (time.Duration, error) {
+	s, err := f.EffectiveString(name)
+	if err != nil {
+		return 0, err
+	}
+	return time.ParseDuration(s)
+}
+
 // isZeroValue guesses whether the string represents the zero
 // value for a flag. It is not accurate but in practice works OK.
 func isZeroValue(value string) bool {
@@ -247,6 +1242,8 @@ func isZeroValue(value string) bool {
 		return true
 	case "0":
 		return true
+	case "[]":
+		return true
 	}
 	return false
 }
@@ -256,6 +1253,8 @@ func isZeroValue(value string) bool {
 // Given "a `name` to show" it returns ("name", "a name to show").
 // If there are no back quotes, the name is an educated guess of the
 // type of the flag's value, or the empty string if the flag is boolean.
+// For a Value that implements TypedValue and is not one this package
+// knows better, the guess is its Type.
 func UnquoteUsage(flag *Flag) (name string, usage string) {
 	// Look for a back-quoted name, but avoid the strings package.
 	usage = flag.Usage
@@ -273,52 +1272,298 @@ func UnquoteUsage(flag *Flag) (name string, usage string) {
 	}
 	// No explicit name, so use type if we can find one.
 	name = "value"
-	switch flag.Value.(type) {
-	case boolFlag:
+	switch v := flag.Value.(type) {
+	case boolFlag, *countValue:
 		name = ""
-	case *durationValue:
+	case *durationValue, *ttlValue:
 		name = "duration"
-	case *float64Value:
+	case *float32Value, *float64Value, *float64RangeValue, *quantityValue:
 		name = "float"
-	case *intValue, *int64Value:
+	case *intValue, *int8Value, *int16Value, *int32Value, *int64Value:
 		name = "int"
-	case *stringValue:
+	case *stringValue, *stringEnumValue:
 		name = "string"
-	case *uintValue, *uint64Value:
+	case *uintValue, *uint8Value, *uint16Value, *uint32Value, *uint64Value:
 		name = "uint"
+	case *stringSliceValue, *stringArrayValue:
+		name = "strings"
+	case *intSliceValue, *int64SliceValue:
+		name = "ints"
+	case *durationSliceValue:
+		name = "durations"
+	case *endpointSliceValue:
+		name = "endpoints"
+	case *stringMapValue:
+		name = "key=value"
+	case *ipValue:
+		name = "ip"
+	case *ipMaskValue:
+		name = "mask"
+	case *bytesHexValue:
+		name = "hex"
+	case *timeValue:
+		name = "time"
+	case *urlValue:
+		name = "url"
+	case TypedValue:
+		name = v.Type()
 	}
 	return
 }
 
+// UsageOf returns the usage string of the named flag, with any back
+// quotes removed as by UnquoteUsage. (FlagSet already has a Usage field,
+// so the method cannot be called Usage.)
+func (f *FlagSet) UsageOf(name string) (string, error) {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return "", fmt.Errorf("no such flag -%v", name)
+	}
+	_, usage := UnquoteUsage(flag)
+	return usage, nil
+}
+
+// UsageOf returns the usage string of the named command-line flag.
+func UsageOf(name string) (string, error) {
+	return CommandLine.UsageOf(name)
+}
+
+// FlagHelp returns a help block for the named flag, suitable for a
+// "help <flag>" page: a line with the flag's names and value type, then
+// its usage, default value and deprecation notice, if any, indented below.
+func (f *FlagSet) FlagHelp(name string) (string, error) {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return "", fmt.Errorf("no such flag -%v", name)
+	}
+	var buf bytes.Buffer
+	if f.shorthandOnly[flag] {
+		fmt.Fprintf(&buf, "-%s", flag.Shorthand)
+	} else if len(flag.Shorthand) > 0 {
+		fmt.Fprintf(&buf, "-%s, --%s", flag.Shorthand, flag.Name)
+	} else {
+		fmt.Fprintf(&buf, "--%s", flag.Name)
+	}
+	typeName, usage := UnquoteUsage(flag)
+	if len(typeName) > 0 {
+		fmt.Fprintf(&buf, " %s", typeName)
+	}
+	buf.WriteString("\n")
+	if len(usage) > 0 {
+		fmt.Fprintf(&buf, "    %s\n", usage)
+	}
+	if _, ok := flag.Value.(*stringValue); ok {
+		fmt.Fprintf(&buf, "    Default: %q\n", flag.DefValue)
+	} else {
+		fmt.Fprintf(&buf, "    Default: %s\n", flag.DefValue)
+	}
+	if len(flag.Deprecated) > 0 {
+		fmt.Fprintf(&buf, "    Deprecated: %s\n", flag.Deprecated)
+	}
+	return buf.String(), nil
+}
+
+// FlagHelp returns a help block for the named command-line flag.
+func FlagHelp(name string) (string, error) {
+	return CommandLine.FlagHelp(name)
+}
+
+// SetAnnotation records values under key in the named flag's Annotations,
+// replacing any values already recorded there. Annotations carry data for
+// tools built on the flag set, such as shell completion, and do not affect
+// parsing.
+func (f *FlagSet) SetAnnotation(name, key string, values []string) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	if flag.Annotations == nil {
+		flag.Annotations = make(map[string][]string)
+	}
+	flag.Annotations[key] = append([]string(nil), values...)
+	return nil
+}
+
+// SetAnnotation records values under key in the named command-line flag's Annotations.
+func SetAnnotation(name, key string, values []string) error {
+	return CommandLine.SetAnnotation(name, key, values)
+}
+
+// GetAnnotation returns the values recorded under key in the named flag's
+// Annotations, and whether there were any.
+func (f *FlagSet) GetAnnotation(name, key string) ([]string, bool, error) {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return nil, false, fmt.Errorf("no such flag -%v", name)
+	}
+	values, ok := flag.Annotations[key]
+	return values, ok, nil
+}
+
 // PrintDefaults prints to standard error the default values of all
 // defined command-line flags in the set. See the documentation for
 // the global function PrintDefaults for more information.
 func (f *FlagSet) PrintDefaults() {
-	f.VisitAll(func(flag *Flag) {
-		s := ""
-		if len(flag.Shorthand) > 0 {
-			s = fmt.Sprintf("  -%s, --%s", flag.Shorthand, flag.Name)
-		} else {
-			s = fmt.Sprintf("  --%s", flag.Name)
-		}
+	fmt.Fprint(f.out(), f.FlagUsages())
+}
+
+// FlagUsages returns the usage message for the flags in the set, one flag
+// per line, with the usage text aligned in a column after the flag names.
+// If any flags are in a help group (see SetFlagGroup), the flags with no
+// group come first and each group follows in a section headed by its
+// name, in the order in which the group's first flag was defined.
+func (f *FlagSet) FlagUsages() string {
+	return f.FlagUsagesWrapped(0)
+}
 
-		name, usage := UnquoteUsage(flag)
-		if len(name) > 0 {
-			s += " " + name
+// FlagUsagesWrapped is like FlagUsages, but wraps the usage text so that
+// lines are at most cols characters wide where possible. If cols is zero
+// or negative, or too small to leave room for the usage text, lines are
+// not wrapped.
+func (f *FlagSet) FlagUsagesWrapped(cols int) string {
+	groups := f.flagGroups()
+	if len(groups) == 0 {
+		return f.flagUsages(func(*Flag) bool { return true }, cols)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(f.flagUsages(func(flag *Flag) bool { return flagGroup(flag) == "" }, cols))
+	for _, group := range groups {
+		usages := f.flagUsages(func(flag *Flag) bool { return flagGroup(flag) == group }, cols)
+		if usages == "" {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
 		}
+		fmt.Fprintf(&buf, "%s:\n%s", group, usages)
+	}
+	return buf.String()
+}
 
-		s += "\n    \t"
-		s += usage
+// flagUsages formats the usage lines of the flags for which include
+// returns true.
+func (f *FlagSet) flagUsages(include func(*Flag) bool, cols int) string {
+	flags, lefts := f.flagColumn(include)
+	maxlen := 0
+	rights := make([]string, len(flags))
+	for i, flag := range flags {
+		_, usage := UnquoteUsage(flag)
+		if e, ok := flag.Value.(*stringEnumValue); ok {
+			usage += fmt.Sprintf(" (one of %s)", strings.Join(e.allowed, ", "))
+		}
+		if r, ok := flag.Value.(*float64RangeValue); ok {
+			usage += fmt.Sprintf(" (range [%v, %v])", r.min, r.max)
+		}
 		if !isZeroValue(flag.DefValue) {
-			if _, ok := flag.Value.(*stringValue); ok {
+			switch flag.Value.(type) {
+			case *stringValue, *stringEnumValue:
 				// put quotes on the value
-				s += fmt.Sprintf(" (default %q)", flag.DefValue)
-			} else {
-				s += fmt.Sprintf(" (default %v)", flag.DefValue)
+				usage += fmt.Sprintf(" (default %q)", flag.DefValue)
+			default:
+				usage += fmt.Sprintf(" (default %v)", flag.DefValue)
 			}
 		}
-		fmt.Fprint(f.out(), s, "\n")
+		if len(lefts[i]) > maxlen {
+			maxlen = len(lefts[i])
+		}
+		rights[i] = usage
+	}
+
+	var buf bytes.Buffer
+	indent := maxlen + 3
+	for i, left := range lefts {
+		buf.WriteString(left)
+		buf.WriteString(strings.Repeat(" ", indent-len(left)))
+		buf.WriteString(wrap(indent, cols, rights[i]))
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// flagColumn returns the flags for which include returns true, leaving
+// out deprecated ones, and the left-hand column of the usage message for
+// each: its shorthand, long name and value placeholder.
+func (f *FlagSet) flagColumn(include func(*Flag) bool) ([]*Flag, []string) {
+	var flags []*Flag
+	var lefts []string
+	// If any flag has a shorthand, indent the others past where it would
+	// be so that every --name starts in the same column. All flags are
+	// looked at, not just the included ones, so that every help group's
+	// section uses the same column.
+	longIndent := "  "
+	f.VisitAll(func(flag *Flag) {
+		if len(flag.Deprecated) == 0 && len(flag.Shorthand) > 0 && len(flag.ShorthandDeprecated) == 0 {
+			longIndent = "      "
+		}
+	})
+	f.VisitAll(func(flag *Flag) {
+		if len(flag.Deprecated) > 0 || !include(flag) {
+			return
+		}
+		left := ""
+		if f.shorthandOnly[flag] {
+			left = fmt.Sprintf("  -%s", flag.Shorthand)
+		} else if len(flag.Shorthand) > 0 && len(flag.ShorthandDeprecated) == 0 {
+			left = fmt.Sprintf("  -%s, --%s", flag.Shorthand, flag.Name)
+		} else {
+			left = fmt.Sprintf("%s--%s", longIndent, flag.Name)
+		}
+		if name, _ := UnquoteUsage(flag); len(name) > 0 {
+			left += " " + name
+		}
+		flags = append(flags, flag)
+		lefts = append(lefts, left)
 	})
+	return flags, lefts
+}
+
+// FlagColumnWidth returns the width of the widest entry, indent included,
+// in the column of flag names and value placeholders that FlagUsages
+// prints before the usage text. FlagUsages starts the usage text three
+// columns after it, so a caller laying out its own table can line up with
+// FlagUsages. FlagUsages aligns each help group's section on its own, so
+// with groups the width is only an upper bound. It returns 0 if there are
+// no flags to list.
+func (f *FlagSet) FlagColumnWidth() int {
+	_, lefts := f.flagColumn(func(*Flag) bool { return true })
+	width := 0
+	for _, left := range lefts {
+		if len(left) > width {
+			width = len(left)
+		}
+	}
+	return width
+}
+
+// FlagColumnWidth returns the width of the column of command-line flag names in the usage message.
+func FlagColumnWidth() int {
+	return CommandLine.FlagColumnWidth()
+}
+
+// wrap wraps s into lines of at most cols characters, breaking at spaces,
+// and indents every line after the first by indent spaces. It returns s
+// unchanged if cols leaves fewer than 24 characters for the text.
+func wrap(indent, cols int, s string) string {
+	width := cols - indent
+	if cols <= 0 || width < 24 {
+		return s
+	}
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			if len(line) > 0 && len(line)+1+len(word) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			if len(line) > 0 {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"+strings.Repeat(" ", indent))
 }
 
 // PrintDefaults prints to standard error the default values of all defined command-line flags.
@@ -326,6 +1571,38 @@ func PrintDefaults() {
 	CommandLine.PrintDefaults()
 }
 
+// FlagUsages returns the usage message for the command-line flags.
+func FlagUsages() string {
+	return CommandLine.FlagUsages()
+}
+
+// ShorthandUsages returns an index of the shorthand letters in the set,
+// one per line in order of the letter, giving the long name each one
+// stands for and its usage.
+func (f *FlagSet) ShorthandUsages() string {
+	letters := make([]int, 0, len(f.shorthands))
+	for c := range f.shorthands {
+		letters = append(letters, int(c))
+	}
+	sort.Ints(letters)
+	var b bytes.Buffer
+	for _, c := range letters {
+		flag := f.shorthands[byte(c)]
+		_, usage := UnquoteUsage(flag)
+		if f.shorthandOnly[flag] {
+			fmt.Fprintf(&b, "  -%c\t%s\n", c, usage)
+			continue
+		}
+		fmt.Fprintf(&b, "  -%c  --%s\t%s\n", c, flag.Name, usage)
+	}
+	return b.String()
+}
+
+// ShorthandUsages returns an index of the command-line shorthand letters.
+func ShorthandUsages() string {
+	return CommandLine.ShorthandUsages()
+}
+
 // defaultUsage is the default function to print a usage message.
 func defaultUsage(f *FlagSet) {
 	if f.name == "" {
@@ -336,14 +1613,74 @@ func defaultUsage(f *FlagSet) {
 	f.PrintDefaults()
 }
 
+// GroupAnnotation is the annotation key holding the help group of a flag,
+// as set by SetFlagGroup or SetAnnotation.
+const GroupAnnotation = "group"
+
+// flagGroup returns the help group of flag, or "" if it has none.
+func flagGroup(flag *Flag) string {
+	if g := flag.Annotations[GroupAnnotation]; len(g) > 0 {
+		return g[0]
+	}
+	return ""
+}
+
+// flagGroups returns the help groups of the flags in the order in which
+// their first member was defined.
+func (f *FlagSet) flagGroups() []string {
+	var groups []string
+	seen := make(map[string]bool)
+	for _, flag := range f.ordered {
+		if g := flagGroup(flag); g != "" && !seen[g] {
+			seen[g] = true
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// groupUsage prints a usage message documenting only the flags in group,
+// in response to --help=group, and returns ErrHelp.
+func (f *FlagSet) groupUsage(group string) error {
+	found := false
+	for _, g := range f.flagGroups() {
+		found = found || g == group
+	}
+	if !found {
+		return f.failf("unknown help group: %s", group)
+	}
+	if f.name == "" {
+		fmt.Fprintf(f.out(), "Usage (%s):\n", group)
+	} else {
+		fmt.Fprintf(f.out(), "Usage of %s (%s):\n", f.name, group)
+	}
+	fmt.Fprint(f.out(), f.flagUsages(func(flag *Flag) bool { return flagGroup(flag) == group }, 0))
+	return ErrHelp
+}
+
+// SetFlagGroup places the named flag in a group, so that --help=group
+// lists only the flags in that group and the usage message lists them in
+// a section of their own. It records the group as the flag's
+// GroupAnnotation.
+func (f *FlagSet) SetFlagGroup(name, group string) error {
+	return f.SetAnnotation(name, GroupAnnotation, []string{group})
+}
+
+// SetFlagGroup places the named command-line flag in a group.
+func SetFlagGroup(name, group string) error {
+	return CommandLine.SetFlagGroup(name, group)
+}
+
 // NOTE: Usage is not just defaultUsage(CommandLine)
 // because it serves (via godoc flag Usage) as the example
 // for how to write your own usage function.
 
-// Usage prints to standard error a usage message documenting all defined command-line flags.
+// Usage prints a usage message documenting all defined command-line flags
+// to CommandLine's output, which is standard error unless changed with
+// CommandLine.SetOutput.
 // The function is a variable that may be changed to point to a custom function.
 var Usage = func() {
-	fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
+	fmt.Fprintf(CommandLine.out(), "Usage of %s:\n", os.Args[0])
 	PrintDefaults()
 }
 
@@ -380,6 +1717,15 @@ func (f *FlagSet) Args() []string { return f.args }
 // Args returns the non-flag command-line arguments.
 func Args() []string { return CommandLine.args }
 
+// ArgsLenAtDash returns the number of arguments in Args that came before
+// the "--" terminator, or -1 if there was no terminator. Args from that
+// index on appeared after it.
+func (f *FlagSet) ArgsLenAtDash() int { return f.argsLenAtDash }
+
+// ArgsLenAtDash returns the number of command-line arguments that came
+// before the "--" terminator, or -1 if there was no terminator.
+func ArgsLenAtDash() int { return CommandLine.argsLenAtDash }
+
 // Var defines a flag with the specified name and usage string. The type and
 // value of the flag are represented by the first argument, of type Value, which
 // typically holds a user-defined implementation of Value. For instance, the
@@ -391,37 +1737,244 @@ func (f *FlagSet) Var(value Value, name string, usage string) {
 }
 
 // Like Var, but accepts a shorthand letter that can be used after a single dash.
+// If name is empty the flag has only the shorthand: it is set with -x but
+// not with any --name, and is listed in the usage message as -x alone.
+// Lookup, Changed and the other methods taking a name find it by its
+// shorthand, so it cannot share that letter with a long flag name.
 func (f *FlagSet) VarP(value Value, name, shorthand, usage string) {
+	err := f.VarPE(value, name, shorthand, usage)
+	if err == nil {
+		return
+	}
+	fmt.Fprintln(f.out(), err)
+	if _, ok := err.(*redefinedError); ok && f.redefinePolicy == RedefineError {
+		return
+	}
+	panic(err.Error())
+}
+
+// VarPE is like VarP, but returns an error rather than panicking if the
+// flag cannot be defined, for example because its name or shorthand is
+// already in use and the redefinition policy does not resolve the clash.
+func (f *FlagSet) VarPE(value Value, name, shorthand, usage string) error {
 	// Remember the default value as a string; it won't change.
-	flag := &Flag{name, shorthand, usage, value, value.String()}
-	_, alreadythere := f.formal[name]
-	if alreadythere {
-		msg := fmt.Sprintf("%s flag redefined: %s", f.name, name)
-		fmt.Fprintln(f.out(), msg)
-		panic(msg) // Happens only if flags are declared with identical names
+	flag := &Flag{Name: name, Shorthand: shorthand, Usage: usage, Value: value, DefValue: value.String()}
+	return f.addFlag(flag)
+}
+
+// AddFlag adds a flag defined elsewhere, such as by a plugin, to the set.
+// Unlike VarP it never panics: a clash with an existing name or shorthand
+// that the redefinition policy does not resolve is returned as an error.
+func (f *FlagSet) AddFlag(flag *Flag) error {
+	if flag.Value == nil {
+		return fmt.Errorf("%s flag %s has no Value", f.name, flag.Name)
+	}
+	return f.addFlag(flag)
+}
+
+// AddFlag adds a flag defined elsewhere to the command-line flag set.
+func AddFlag(flag *Flag) error {
+	return CommandLine.AddFlag(flag)
+}
+
+// ReplaceValue makes value the Value of the named flag, for example to
+// wrap the original one, and takes the flag's default from value's
+// String. The flag keeps its name, shorthand and other settings, and
+// whether it has been set.
+func (f *FlagSet) ReplaceValue(name string, value Value) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	if value == nil {
+		return fmt.Errorf("%s flag %s has no Value", f.name, flag.Name)
+	}
+	flag.Value = value
+	flag.DefValue = value.String()
+	f.saveDefault(flag)
+	return nil
+}
+
+// ReplaceValue makes value the Value of the named command-line flag.
+func ReplaceValue(name string, value Value) error {
+	return CommandLine.ReplaceValue(name, value)
+}
+
+// addFlag registers flag in the set, applying the redefinition policy to
+// clashes with existing names and shorthands. The set is left unchanged
+// if an error is returned.
+func (f *FlagSet) addFlag(flag *Flag) error {
+	shorthandOnly := flag.Name == ""
+	if shorthandOnly {
+		if flag.Shorthand == "" {
+			return fmt.Errorf("%s flag has neither a name nor a shorthand", f.name)
+		}
+		flag.Name = flag.Shorthand
+	}
+	flag.Name = string(f.normalizeFlagName(flag.Name))
+	if len(flag.Shorthand) > 1 {
+		return fmt.Errorf("%s shorthand more than ASCII character: %s", f.name, flag.Shorthand)
+	}
+	if len(flag.Shorthand) == 1 && !isShorthand(flag.Shorthand[0]) {
+		return fmt.Errorf("%s shorthand for %s must be an ASCII letter or digit: %q", f.name, flag.Name, flag.Shorthand[0])
+	}
+	old, nameTaken := f.formal[flag.Name]
+	var holder *Flag
+	if len(flag.Shorthand) == 1 {
+		holder = f.shorthands[flag.Shorthand[0]]
+	}
+	switch f.redefinePolicy {
+	case RedefineIgnore:
+		if nameTaken {
+			return nil
+		}
+		if holder != nil {
+			flag.Shorthand = ""
+		}
+	case RedefineReplace:
+		if nameTaken {
+			f.removeFlag(old)
+		}
+		if holder != nil && holder != old {
+			delete(f.shorthands, holder.Shorthand[0])
+			holder.Shorthand = ""
+		}
+	default:
+		if nameTaken {
+			return &redefinedError{fmt.Sprintf("%s flag redefined: %s", f.name, flag.Name)}
+		}
+		if holder != nil {
+			return &redefinedError{fmt.Sprintf("%s shorthand reused: %q for %s already used for %s", f.name, flag.Shorthand[0], flag.Name, holder.Name)}
+		}
 	}
+
 	if f.formal == nil {
 		f.formal = make(map[string]*Flag)
 	}
-	f.formal[name] = flag
+	f.formal[flag.Name] = flag
+	f.ordered = append(f.ordered, flag)
+	if shorthandOnly {
+		if f.shorthandOnly == nil {
+			f.shorthandOnly = make(map[*Flag]bool)
+		}
+		f.shorthandOnly[flag] = true
+	}
+	f.saveDefault(flag)
+	if len(flag.Shorthand) == 0 {
+		return nil
+	}
+	if f.shorthands == nil {
+		f.shorthands = make(map[byte]*Flag)
+	}
+	f.shorthands[flag.Shorthand[0]] = flag
+	return nil
+}
+
+// isShorthand reports whether c may be used as a shorthand. Other bytes,
+// such as '-' and '=', would make the flag impossible to parse.
+func isShorthand(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// AddFlagSet adds the flags defined in newSet to f. Flags whose names are
+// already defined in f are left untouched. The added flags share their
+// Values with newSet, so parsing either set updates the same variables,
+// but get their own copy of its Annotations. A flag whose shorthand is
+// already in use in f is added without it.
+func (f *FlagSet) AddFlagSet(newSet *FlagSet) {
+	if newSet == nil {
+		return
+	}
+	newSet.VisitAllInOrder(func(flag *Flag) {
+		if _, exists := f.lookup(flag.Name); exists {
+			return
+		}
+		added := &Flag{
+			Name:                flag.Name,
+			Shorthand:           flag.Shorthand,
+			Usage:               flag.Usage,
+			Value:               flag.Value,
+			DefValue:            flag.DefValue,
+			Deprecated:          flag.Deprecated,
+			ShorthandDeprecated: flag.ShorthandDeprecated,
+			NoOptDefVal:         flag.NoOptDefVal,
+		}
+		if flag.Annotations != nil {
+			added.Annotations = make(map[string][]string, len(flag.Annotations))
+			for key, values := range flag.Annotations {
+				added.Annotations[key] = append([]string(nil), values...)
+			}
+		}
+		if len(added.Shorthand) == 1 && f.shorthands[added.Shorthand[0]] != nil {
+			if newSet.shorthandOnly[flag] {
+				return
+			}
+			added.Shorthand = ""
+		}
+		if newSet.shorthandOnly[flag] {
+			added.Name = ""
+		}
+		f.addFlag(added)
+	})
+}
 
-	if len(shorthand) == 0 {
-		return
+// removeFlag forgets a defined flag, freeing its name and shorthand.
+func (f *FlagSet) removeFlag(flag *Flag) {
+	delete(f.formal, flag.Name)
+	for i, o := range f.ordered {
+		if o == flag {
+			f.ordered = append(f.ordered[:i], f.ordered[i+1:]...)
+			break
+		}
 	}
-	if len(shorthand) > 1 {
-		fmt.Fprintf(f.out(), "%s shorthand more than ASCII character: %s\n", f.name, shorthand)
-		panic("shorthand is more than one character")
+	delete(f.actual, flag.Name)
+	delete(f.shorthandOnly, flag)
+	delete(f.defaults, flag)
+	if len(flag.Shorthand) == 1 && f.shorthands[flag.Shorthand[0]] == flag {
+		delete(f.shorthands, flag.Shorthand[0])
 	}
-	if f.shorthands == nil {
-		f.shorthands = make(map[byte]*Flag)
+	for key, ci := range f.caseInsensitive {
+		if ci == flag {
+			delete(f.caseInsensitive, key)
+		}
 	}
-	c := shorthand[0]
-	old, alreadythere := f.shorthands[c]
-	if alreadythere {
-		fmt.Fprintf(f.out(), "%s shorthand reused: %q for %s already used for %s\n", f.name, c, name, old.Name)
-		panic("shorthand redefinition")
+	for alias, a := range f.aliases {
+		if a == flag {
+			delete(f.aliases, alias)
+		}
 	}
-	f.shorthands[c] = flag
+}
+
+// A redefinedError reports a flag name or shorthand that is already in use.
+type redefinedError struct {
+	msg string
+}
+
+func (e *redefinedError) Error() string { return e.msg }
+
+// RedefinePolicy controls what happens when a flag is defined with a name
+// or shorthand that is already in use.
+type RedefinePolicy int
+
+const (
+	// RedefinePanic makes VarP panic on a clash. It is the default.
+	RedefinePanic RedefinePolicy = iota
+	// RedefineError makes VarP report the clash to the output and keep the
+	// existing definition, rather than panicking.
+	RedefineError
+	// RedefineIgnore keeps the existing definition silently. A new flag
+	// whose shorthand is taken is defined without a shorthand.
+	RedefineIgnore
+	// RedefineReplace makes the new definition win, removing a flag with
+	// the same name and taking the shorthand from any flag that holds it.
+	RedefineReplace
+)
+
+// SetRedefinePolicy sets how the flag set handles a flag being defined
+// with a name or shorthand that is already in use. VarPE returns an error
+// for a clash under both RedefinePanic and RedefineError.
+func (f *FlagSet) SetRedefinePolicy(policy RedefinePolicy) {
+	f.redefinePolicy = policy
 }
 
 // Var defines a flag with the specified name and usage string. The type and
@@ -439,10 +1992,25 @@ func VarP(value Value, name, shorthand, usage string) {
 	CommandLine.VarP(value, name, shorthand, usage)
 }
 
+// VarPE is like VarP, but returns an error rather than panicking if the
+// command-line flag cannot be defined.
+func VarPE(value Value, name, shorthand, usage string) error {
+	return CommandLine.VarPE(value, name, shorthand, usage)
+}
+
 // failf prints to standard error a formatted error and usage message and
 // returns the error.
 func (f *FlagSet) failf(format string, a ...interface{}) error {
-	err := fmt.Errorf(format, a...)
+	return f.fail(fmt.Errorf(format, a...))
+}
+
+// failp is like failf, but returns a ParseError of the given kind.
+func (f *FlagSet) failp(kind ParseErrorKind, flag, token string, err error, format string, a ...interface{}) error {
+	return f.fail(&ParseError{Kind: kind, Flag: flag, Token: token, Err: err, msg: fmt.Sprintf(format, a...)})
+}
+
+// fail prints err and a usage message and returns err.
+func (f *FlagSet) fail(err error) error {
 	fmt.Fprintln(f.out(), err)
 	f.usage()
 	return err
@@ -461,24 +2029,68 @@ func (f *FlagSet) usage() {
 }
 
 func (f *FlagSet) setFlag(flag *Flag, value string, origArg string) error {
-	if err := flag.Value.Set(value); err != nil {
-		return f.failf("invalid argument %q for %s: %v", value, origArg, err)
+	if f.DisallowDuplicates && f.given[flag] && !accumulates(flag.Value) {
+		return f.failp(DuplicateFlag, flag.Name, origArg, nil, "flag --%s given more than once", flag.Name)
 	}
-	// mark as visited for Visit()
-	if f.actual == nil {
-		f.actual = make(map[string]*Flag)
+	if err := f.setFrom(flag, value, SourceCommandLine); err != nil {
+		return f.failp(InvalidValue, flag.Name, origArg, err, "invalid argument %q for %s: %v", value, origArg, err)
+	}
+	if f.given == nil {
+		f.given = make(map[*Flag]bool)
+	}
+	f.given[flag] = true
+	if len(flag.Deprecated) > 0 {
+		f.warnDeprecated("--"+flag.Name, fmt.Sprintf("Flag --%s has been deprecated, %s", flag.Name, flag.Deprecated))
 	}
-	f.actual[flag.Name] = flag
-
 	return nil
 }
 
+// warnDeprecated prints warning the first time the deprecated form, a
+// --name or -shorthand, is used.
+func (f *FlagSet) warnDeprecated(form, warning string) {
+	if f.warned[form] {
+		return
+	}
+	fmt.Fprintln(f.out(), warning)
+	if f.warned == nil {
+		f.warned = make(map[string]bool)
+	}
+	f.warned[form] = true
+}
+
+// accumulates reports whether setting v adds to its value rather than
+// replacing it, as for slice, map and count flags.
+func accumulates(v Value) bool {
+	if _, ok := v.(restorer); ok {
+		return true
+	}
+	_, ok := v.(*countValue)
+	return ok
+}
+
+// impliedValue returns the value a flag takes when it appears on the
+// command line without one, and whether it may appear that way at all.
+func impliedValue(flag *Flag) (string, bool) {
+	if flag.NoOptDefVal != "" {
+		return flag.NoOptDefVal, true
+	}
+	switch v := flag.Value.(type) {
+	case *countValue:
+		return "+1", true
+	case boolFlag:
+		if v.IsBoolFlag() {
+			return "true", true
+		}
+	}
+	return "", false
+}
+
 func (f *FlagSet) parseArgs(args []string) error {
 	for len(args) > 0 {
 		s := args[0]
 		args = args[1:]
 		if len(s) == 0 || s[0] != '-' || len(s) == 1 {
-			if !f.interspersed {
+			if !f.interspersed || f.rawTail {
 				f.args = append(f.args, s)
 				f.args = append(f.args, args...)
 				return nil
@@ -487,80 +2099,378 @@ func (f *FlagSet) parseArgs(args []string) error {
 			continue
 		}
 
+		var passthrough *FlagSet
+		var greedy *Flag
 		if s[1] == '-' {
 			if len(s) == 2 { // "--" terminates the flags
+				f.argsLenAtDash = len(f.args)
 				f.args = append(f.args, args...)
 				return nil
 			}
 			name := s[2:]
 			if len(name) == 0 || name[0] == '-' || name[0] == '=' {
-				return f.failf("bad flag syntax: %s", s)
+				return f.failp(BadSyntax, "", s, nil, "bad flag syntax: %s", s)
 			}
 			split := strings.SplitN(name, "=", 2)
 			name = split[0]
-			m := f.formal
-			flag, alreadythere := m[name] // BUG
+			flag, alreadythere := f.lookup(name)
+			if alreadythere && f.shorthandOnly[flag] {
+				flag, alreadythere = nil, false
+			}
+			if !alreadythere && strings.HasPrefix(name, "no-") {
+				if nf, ok := f.lookup(name[3:]); ok && f.negatable[nf] {
+					if len(split) == 2 {
+						return f.failp(BadSyntax, nf.Name, s, nil, "flag --%s does not take a value", name)
+					}
+					if err := f.setFlag(nf, "false", s); err != nil {
+						return err
+					}
+					continue
+				}
+			}
 			if !alreadythere {
 				if name == "help" { // special case for nice help message.
+					if len(split) == 2 {
+						return f.groupUsage(split[1])
+					}
 					f.usage()
 					return ErrHelp
 				}
-				return f.failf("unknown flag: --%s", name)
+				if f.AllowPrefixMatch {
+					var err error
+					if flag, err = f.prefixMatch(name); err != nil {
+						return err
+					}
+					alreadythere = flag != nil
+				}
+			}
+			if !alreadythere {
+				if f.rawTail {
+					f.args = append(f.args, s)
+					f.args = append(f.args, args...)
+					return nil
+				}
+				return f.failp(UnknownFlag, name, s, nil, "unknown flag: --%s", name)
+			}
+			passthrough = f.passthrough[flag]
+			if f.greedyMaps[flag] {
+				greedy = flag
 			}
 			if len(split) == 1 {
-				if bv, ok := flag.Value.(boolFlag); !ok || !bv.IsBoolFlag() {
-					return f.failf("flag needs an argument: %s", s)
+				if implied, ok := impliedValue(flag); ok {
+					if err := f.setFlag(flag, implied, s); err != nil {
+						return err
+					}
+				} else if len(args) > 0 {
+					// The next argument is the value, even if it begins
+					// with a dash (e.g. a negative number or duration).
+					if err := f.setFlag(flag, args[0], s); err != nil {
+						return err
+					}
+					args = args[1:]
+				} else {
+					return f.failp(MissingValue, flag.Name, s, nil, "flag needs an argument: %s", s)
 				}
-				f.setFlag(flag, "true", s)
 			} else {
 				if err := f.setFlag(flag, split[1], s); err != nil {
 					return err
 				}
 			}
 		} else {
-			shorthands := s[1:]
-			for i := 0; i < len(shorthands); i++ {
-				c := shorthands[i]
-				flag, alreadythere := f.shorthands[c]
-				if !alreadythere {
-					if c == 'h' { // special case for nice help message.
-						f.usage()
-						return ErrHelp
-					}
-					return f.failf("unknown shorthand flag: %q in -%s", c, shorthands)
-				}
-				if bv, ok := flag.Value.(boolFlag); ok && bv.IsBoolFlag() {
-					f.setFlag(flag, "true", s)
-					continue
-				}
-				if i < len(shorthands)-1 {
-					if err := f.setFlag(flag, shorthands[i+1:], s); err != nil {
-						return err
-					}
-					break
-				}
-				if len(args) == 0 {
-					return f.failf("flag needs an argument: %q in -%s", c, shorthands)
+			if _, known := f.shorthands[s[1]]; !known && f.rawTail && s[1] != 'h' {
+				f.args = append(f.args, s)
+				f.args = append(f.args, args...)
+				return nil
+			}
+			var flags []*Flag
+			var err error
+			if args, flags, err = f.parseShortArg(s, args); err != nil {
+				return err
+			}
+			for _, flag := range flags {
+				if t, ok := f.passthrough[flag]; ok {
+					passthrough = t
 				}
-				if err := f.setFlag(flag, args[0], s); err != nil {
-					return err
+				if f.greedyMaps[flag] {
+					greedy = flag
 				}
-				args = args[1:]
-				break // should be unnecessary
 			}
 		}
+		if greedy != nil {
+			var err error
+			if args, err = f.consumeGreedy(greedy, args); err != nil {
+				return err
+			}
+		}
+		if passthrough != nil {
+			return passthrough.Parse(args)
+		}
+	}
+	return nil
+}
+
+// parseShortArg parses s, a cluster of shorthands such as -abc, and returns
+// the remaining arguments and the flags that were set. The rules are:
+// shorthands that need no value (bools, counts and flags with a
+// NoOptDefVal) consume nothing, so the next letter is another shorthand;
+// the first shorthand that takes a value consumes the rest of the cluster,
+// after an optional '=', or the next argument if the cluster ends there.
+// So with bool -a and string -b, -ab=c, -abc and -a -b c all set b to "c".
+// An '=' or '-' anywhere else is a syntax error.
+func (f *FlagSet) parseShortArg(s string, args []string) ([]string, []*Flag, error) {
+	var flags []*Flag
+	shorthands := s[1:]
+	for i := 0; i < len(shorthands); i++ {
+		c := shorthands[i]
+		if c == '=' || c == '-' {
+			return args, flags, f.failp(BadSyntax, "", s, nil, "bad flag syntax: %s", s)
+		}
+		flag, alreadythere := f.shorthands[c]
+		if !alreadythere {
+			if c == 'h' { // special case for nice help message.
+				f.usage()
+				return args, flags, ErrHelp
+			}
+			return args, flags, f.failp(UnknownFlag, string(c), s, nil, "unknown shorthand flag: %q in -%s", c, shorthands)
+		}
+		flags = append(flags, flag)
+		if len(flag.ShorthandDeprecated) > 0 {
+			f.warnDeprecated("-"+flag.Shorthand, fmt.Sprintf("Flag shorthand -%s has been deprecated, %s", flag.Shorthand, flag.ShorthandDeprecated))
+		}
+		if implied, ok := impliedValue(flag); ok {
+			if i+1 < len(shorthands) && shorthands[i+1] == '=' {
+				return args, flags, f.setFlag(flag, shorthands[i+2:], s)
+			}
+			if err := f.setFlag(flag, implied, s); err != nil {
+				return args, flags, err
+			}
+			continue
+		}
+		if value := shorthands[i+1:]; len(value) > 0 {
+			if value[0] == '=' {
+				value = value[1:]
+			}
+			return args, flags, f.setFlag(flag, value, s)
+		}
+		if len(args) == 0 {
+			return args, flags, f.failp(MissingValue, flag.Name, s, nil, "flag needs an argument: %q in -%s", c, shorthands)
+		}
+		return args[1:], flags, f.setFlag(flag, args[0], s)
+	}
+	return args, flags, nil
+}
+
+// PassthroughAfter arranges for the arguments following the named flag to
+// be parsed by target rather than by f. When the flag is seen it is set as
+// usual, and everything after it on the command line is handed to
+// target.Parse; the results, including any positional arguments, are
+// found in target.
+func (f *FlagSet) PassthroughAfter(name string, target *FlagSet) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	if f.passthrough == nil {
+		f.passthrough = make(map[*Flag]*FlagSet)
+	}
+	f.passthrough[flag] = target
+	return nil
+}
+
+// BindPositional arranges for the named flag to be filled from the
+// positional argument at index if the flag was not set while parsing.
+// The index refers to Args() as it stands after flags have been parsed.
+// A positional argument that fills a flag is consumed: it is removed from
+// Args(), and the remaining arguments shift down to take its place.
+func (f *FlagSet) BindPositional(index int, name string) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	if index < 0 {
+		return fmt.Errorf("invalid positional index %d for -%v", index, name)
+	}
+	if f.positionals == nil {
+		f.positionals = make(map[int]*Flag)
+	}
+	f.positionals[index] = flag
+	return nil
+}
+
+// BindPositional arranges for the named command-line flag to be filled
+// from the positional argument at index if it was not otherwise set.
+func BindPositional(index int, name string) error {
+	return CommandLine.BindPositional(index, name)
+}
+
+// bindPositionals fills unset flags from their bound positional arguments.
+// Indexes are processed from highest to lowest so that consuming one
+// argument does not shift the ones still to be examined.
+func (f *FlagSet) bindPositionals() error {
+	indexes := make([]int, 0, len(f.positionals))
+	for i := range f.positionals {
+		indexes = append(indexes, i)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(indexes)))
+	for _, i := range indexes {
+		flag := f.positionals[i]
+		if _, set := f.actual[flag.Name]; set || i >= len(f.args) {
+			continue
+		}
+		if err := f.setFlag(flag, f.args[i], f.args[i]); err != nil {
+			return err
+		}
+		f.args = append(f.args[:i], f.args[i+1:]...)
+		if i < f.argsLenAtDash {
+			f.argsLenAtDash--
+		}
+	}
+	return nil
+}
+
+// flagGroup resolves names to defined flags.
+func (f *FlagSet) flagGroup(names []string) ([]*Flag, error) {
+	group := make([]*Flag, len(names))
+	for i, name := range names {
+		flag, ok := f.lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("no such flag -%v", name)
+		}
+		group[i] = flag
+	}
+	return group, nil
+}
+
+// MarkRequiredOneOf requires that at least one of the named flags be set
+// when the flag set is parsed. Combined with MarkMutuallyExclusive on the
+// same names, it requires exactly one of them.
+func (f *FlagSet) MarkRequiredOneOf(names ...string) error {
+	group, err := f.flagGroup(names)
+	if err != nil {
+		return err
+	}
+	f.requiredOneOf = append(f.requiredOneOf, group)
+	return nil
+}
+
+// MarkRequiredOneOf requires that at least one of the named command-line flags be set.
+func MarkRequiredOneOf(names ...string) error {
+	return CommandLine.MarkRequiredOneOf(names...)
+}
+
+// MarkMutuallyExclusive requires that at most one of the named flags be
+// set when the flag set is parsed.
+func (f *FlagSet) MarkMutuallyExclusive(names ...string) error {
+	group, err := f.flagGroup(names)
+	if err != nil {
+		return err
+	}
+	f.exclusive = append(f.exclusive, group)
+	return nil
+}
+
+// MarkMutuallyExclusive requires that at most one of the named command-line flags be set.
+func MarkMutuallyExclusive(names ...string) error {
+	return CommandLine.MarkMutuallyExclusive(names...)
+}
+
+// MarkRequired requires that the named flag be set when the flag set is
+// parsed. Parse reports all missing required flags in a single error.
+func (f *FlagSet) MarkRequired(name string) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	if f.required == nil {
+		f.required = make(map[*Flag]bool)
+	}
+	f.required[flag] = true
+	return nil
+}
+
+// MarkRequired requires that the named command-line flag be set.
+func MarkRequired(name string) error {
+	return CommandLine.MarkRequired(name)
+}
+
+// checkRequired verifies that every required flag has been set.
+func (f *FlagSet) checkRequired() error {
+	var missing []string
+	for flag := range f.required {
+		if _, ok := f.actual[flag.Name]; !ok {
+			missing = append(missing, flag.Name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return f.failf("required flags [%s] not set", strings.Join(missing, " "))
+}
+
+// checkGroups verifies the constraints on groups of flags after parsing.
+func (f *FlagSet) checkGroups() error {
+	for _, group := range f.requiredOneOf {
+		if len(f.setIn(group)) == 0 {
+			return f.failf("at least one of the flags [%s] must be set", strings.Join(flagNames(group), " "))
+		}
+	}
+	for _, group := range f.exclusive {
+		if set := f.setIn(group); len(set) > 1 {
+			return f.failf("flags [%s] are mutually exclusive but [%s] were set", strings.Join(flagNames(group), " "), strings.Join(flagNames(set), " "))
+		}
 	}
 	return nil
 }
 
+// setIn returns the flags in group that have been set.
+func (f *FlagSet) setIn(group []*Flag) []*Flag {
+	var set []*Flag
+	for _, flag := range group {
+		if _, ok := f.actual[flag.Name]; ok {
+			set = append(set, flag)
+		}
+	}
+	return set
+}
+
+func flagNames(flags []*Flag) []string {
+	names := make([]string, len(flags))
+	for i, flag := range flags {
+		names[i] = flag.Name
+	}
+	return names
+}
+
 // Parse parses flag definitions from the argument list, which should not
 // include the command name.  Must be called after all flags in the FlagSet
 // are defined and before flags are accessed by the program.
-// The return value will be ErrHelp if -help was set but not defined.
+// The return value will be ErrHelp if --help or -h was set but not defined;
+// a flag defined with either name is set like any other instead.
 func (f *FlagSet) Parse(arguments []string) error {
 	f.parsed = true
 	f.args = make([]string, 0, len(arguments))
-	err := f.parseArgs(arguments)
+	f.argsLenAtDash = -1
+	f.given = nil
+	var err error
+	if f.expandFiles {
+		arguments, err = f.expandArgFiles(arguments)
+	}
+	if err == nil {
+		err = f.parseArgs(arguments)
+	}
+	if err == nil {
+		err = f.bindPositionals()
+	}
+	if err == nil {
+		err = f.applyEnv()
+	}
+	if err == nil {
+		err = f.checkRequired()
+	}
+	if err == nil {
+		err = f.checkGroups()
+	}
 	if err != nil {
 		switch f.errorHandling {
 		case ContinueOnError:
@@ -574,11 +2484,141 @@ func (f *FlagSet) Parse(arguments []string) error {
 	return nil
 }
 
+// Validate checks arguments as Parse would, reporting unknown flags, bad
+// values and missing required flags, but without changing f: the flag
+// values, which flags are set, and Args are the same afterwards. The
+// arguments are parsed into copies of the flags' values. The values of
+// types defined outside this package cannot be copied, so they are not
+// set at all and a value they would reject is not reported. Nothing is
+// printed and no change notifications are sent. Flag sets that take over
+// parsing through PassthroughAfter are not protected.
+func (f *FlagSet) Validate(arguments []string) error {
+	values := make(map[*Flag]Value, len(f.formal))
+	sources := make(map[*Flag]Source, len(f.formal))
+	for _, flag := range f.formal {
+		values[flag], sources[flag] = flag.Value, flag.Source
+		flag.Value = validationValue(flag.Value)
+	}
+	actual := f.actual
+	f.actual = make(map[string]*Flag, len(actual))
+	for name, flag := range actual {
+		f.actual[name] = flag
+	}
+	warned := f.warned
+	f.warned = make(map[string]bool, len(warned))
+	for form := range warned {
+		f.warned[form] = true
+	}
+	immutable := f.immutable
+	f.immutable = make(map[*Flag]bool, len(immutable))
+	for flag, set := range immutable {
+		f.immutable[flag] = set
+	}
+	args, argsLenAtDash, parsed, given := f.args, f.argsLenAtDash, f.parsed, f.given
+	output, usage, changes, errorHandling := f.output, f.Usage, f.changes, f.errorHandling
+	f.output, f.Usage, f.changes, f.errorHandling = ioutil.Discard, func() {}, nil, ContinueOnError
+
+	err := f.Parse(arguments)
+
+	f.output, f.Usage, f.changes, f.errorHandling = output, usage, changes, errorHandling
+	f.args, f.argsLenAtDash, f.parsed, f.given = args, argsLenAtDash, parsed, given
+	f.actual, f.warned, f.immutable = actual, warned, immutable
+	for flag, value := range values {
+		flag.Value, flag.Source = value, sources[flag]
+	}
+	return err
+}
+
+// ParseDir sets flags from the files in dir, in the style of a Kubernetes
+// downward API or config map volume: a file named after a flag holds that
+// flag's value, with surrounding whitespace trimmed. Files that do not name
+// a flag are ignored, as are flags that have already been set. As with
+// ApplyJSON, the values prime the flags without counting as set, so the
+// command line takes precedence whichever of Parse and ParseDir is called
+// first.
+func (f *FlagSet) ParseDir(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range files {
+		if fi.IsDir() {
+			continue
+		}
+		flag, ok := f.lookup(fi.Name())
+		if !ok {
+			continue
+		}
+		if _, set := f.actual[flag.Name]; set {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			return err
+		}
+		value := strings.TrimSpace(string(data))
+		if err := f.prime(flag, value); err != nil {
+			return fmt.Errorf("invalid value %q for flag -%s in %s: %v", value, flag.Name, dir, err)
+		}
+	}
+	return nil
+}
+
+// ParseDir sets command-line flags that have not already been set from the files in dir.
+func ParseDir(dir string) error {
+	return CommandLine.ParseDir(dir)
+}
+
 // Parsed reports whether f.Parse has been called.
 func (f *FlagSet) Parsed() bool {
 	return f.parsed
 }
 
+// Reset forgets which flags have been set and the arguments left over from
+// parsing, and marks f as not parsed, so that Parse can be called again
+// with another argument list. The flags that were set report SourceDefault
+// again, and immutable flags may be set once more. The flag definitions
+// are kept. Flag values are not reset: a flag that is not given again
+// keeps the value it was last set to, so callers must restore defaults
+// themselves if they need them.
+func (f *FlagSet) Reset() {
+	for _, flag := range f.actual {
+		flag.Source = SourceDefault
+	}
+	for flag := range f.immutable {
+		f.immutable[flag] = false
+	}
+	f.actual = nil
+	f.args = nil
+	f.argsLenAtDash = -1
+	f.parsed = false
+}
+
+// ResetFlag sets the named flag back to its default value and forgets that
+// it was set, so that Changed reports false for it again.
+func (f *FlagSet) ResetFlag(name string) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	if restore, ok := f.defaults[flag]; ok {
+		restore()
+	} else if err := restoreValue(flag.Value, flag.DefValue); err != nil {
+		return fmt.Errorf("invalid default %q for flag -%v: %v", flag.DefValue, name, err)
+	}
+	delete(f.actual, flag.Name)
+	flag.Source = SourceDefault
+	return nil
+}
+
+// ResetFlag sets the named command-line flag back to its default value and forgets that it was set.
+func ResetFlag(name string) error {
+	return CommandLine.ResetFlag(name)
+}
+
 // Parse parses the command-line flags from os.Args[1:].  Must be called
 // after all flags are defined and before flags are accessed by the program.
 func Parse() {
@@ -591,6 +2631,18 @@ func SetInterspersed(interspersed bool) {
 	CommandLine.SetInterspersed(interspersed)
 }
 
+// SetExpandEnvValues controls whether command-line flag values have $VAR
+// references expanded before they are set.
+func SetExpandEnvValues(expand bool) {
+	CommandLine.SetExpandEnvValues(expand)
+}
+
+// SetExpandArgsFromFile controls whether Parse replaces @file arguments
+// to the command line with the words read from the file.
+func SetExpandArgsFromFile(expand bool) {
+	CommandLine.SetExpandArgsFromFile(expand)
+}
+
 // Parsed returns true if the command-line flags have been parsed.
 func Parsed() bool {
 	return CommandLine.Parsed()
@@ -606,19 +2658,136 @@ func NewFlagSet(name string, errorHandling ErrorHandling) *FlagSet {
 		name:          name,
 		errorHandling: errorHandling,
 		interspersed:  true,
+		argsLenAtDash: -1,
+		SortFlags:     true,
 	}
 	return f
 }
 
+// SetExpandEnvValues controls whether $VAR and ${VAR} references in flag
+// values are expanded with os.ExpandEnv before the value is set. It is off
+// by default. When it is on, every value is expanded, so a literal dollar
+// sign cannot be passed through: "--price=$5" becomes "--price=" if $5 is
+// unset, and there is no escape for it.
+func (f *FlagSet) SetExpandEnvValues(expand bool) {
+	f.expandEnv = expand
+}
+
 // Whether to support interspersed option/non-option arguments.
 func (f *FlagSet) SetInterspersed(interspersed bool) {
 	f.interspersed = interspersed
 }
 
-// Init sets the name and error handling property for a flag set.
+// SetPassthroughAfterFirstPositional makes Parse stop at the first
+// positional argument or unknown flag and keep it, along with everything
+// after it, verbatim in Args, without needing a "--" terminator. This
+// suits wrappers that pass the rest of their command line to another
+// program. Flags before that point are parsed as usual.
+func (f *FlagSet) SetPassthroughAfterFirstPositional(passthrough bool) {
+	f.rawTail = passthrough
+}
+
+// Default limits on expanding arguments from files.
+const (
+	DefaultFileExpansionDepth = 10
+	DefaultFileExpansionBytes = 10 << 20
+)
+
+// SetFileExpansionLimits limits how deeply files read for arguments may
+// name further files, and how many bytes each of them may hold, so that
+// a recursive or oversized file yields an error instead of exhausting
+// memory. A limit of zero or less restores its default.
+func (f *FlagSet) SetFileExpansionLimits(maxDepth int, maxBytes int64) {
+	if maxDepth < 0 {
+		maxDepth = 0
+	}
+	if maxBytes < 0 {
+		maxBytes = 0
+	}
+	f.fileMaxDepth, f.fileMaxBytes = maxDepth, maxBytes
+}
+
+// SetExpandArgsFromFile controls whether Parse replaces each argument of
+// the form @path with the whitespace-separated words read from the file at
+// path, for command lines too long to pass directly. Words in the file
+// that begin with @ are expanded in turn, up to the nesting depth set by
+// SetFileExpansionLimits; a depth of 1 expands only the files named on the
+// command line and makes any nested @file an error. Arguments after "--",
+// and a lone "@", are left alone. It is off by default.
+func (f *FlagSet) SetExpandArgsFromFile(expand bool) {
+	f.expandFiles = expand
+}
+
+// expandArgFiles returns args with each @file argument replaced by the
+// words of the file.
+func (f *FlagSet) expandArgFiles(args []string) ([]string, error) {
+	maxDepth, maxBytes := f.fileExpansionLimits()
+	var out []string
+	dash := false
+	var expand func(args []string, depth int) error
+	expand = func(args []string, depth int) error {
+		for _, arg := range args {
+			if dash || len(arg) < 2 || arg[0] != '@' {
+				dash = dash || arg == "--"
+				out = append(out, arg)
+				continue
+			}
+			path := arg[1:]
+			if depth >= maxDepth {
+				return f.failf("@%s nested more than %d files deep", path, maxDepth)
+			}
+			words, err := readArgFile(path, maxBytes)
+			if err != nil {
+				return f.failf("reading arguments from @%s: %v", path, err)
+			}
+			if err := expand(words, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := expand(args, 0); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// readArgFile returns the whitespace-separated words of the file at path,
+// which must hold no more than maxBytes bytes.
+func readArgFile(path string, maxBytes int64) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxBytes)
+	}
+	return strings.Fields(string(data)), nil
+}
+
+// fileExpansionLimits returns the limits in effect for file expansion.
+func (f *FlagSet) fileExpansionLimits() (maxDepth int, maxBytes int64) {
+	maxDepth, maxBytes = f.fileMaxDepth, f.fileMaxBytes
+	if maxDepth == 0 {
+		maxDepth = DefaultFileExpansionDepth
+	}
+	if maxBytes == 0 {
+		maxBytes = DefaultFileExpansionBytes
+	}
+	return maxDepth, maxBytes
+}
+
+// Init sets the name and error handling property for a flag set, and
+// turns on SortFlags as NewFlagSet does.
 // By default, the zero FlagSet uses an empty name and the
 // ContinueOnError error handling policy.
 func (f *FlagSet) Init(name string, errorHandling ErrorHandling) {
 	f.name = name
 	f.errorHandling = errorHandling
+	f.SortFlags = true
 }
@@ -6,9 +6,16 @@ package pflag
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -348,3 +355,2851 @@ func TestNoInterspersed(t *testing.T) {
 		t.Fatal("expected interspersed options/non-options to fail")
 	}
 }
+
+func TestMarkCaseInsensitive(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	verbose := f.Bool("verbose", false, "verbose output")
+	name := f.String("name", "", "a name")
+	if err := f.MarkCaseInsensitive("verbose"); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if err := f.Parse([]string{"--VerBose"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if !*verbose {
+		t.Error("verbose flag should be true after --VerBose")
+	}
+	if f.Lookup("VERBOSE") == nil {
+		t.Error("Lookup should find marked flag ignoring case")
+	}
+	if f.Lookup("NAME") != nil {
+		t.Error("Lookup should not find unmarked flag ignoring case")
+	}
+	if err := f.Parse([]string{"--Name=x"}); err == nil {
+		t.Error("expected error for unmarked flag in mixed case")
+	}
+	if *name != "" {
+		t.Errorf("name flag should be unset, is %q", *name)
+	}
+}
+
+func TestMarkCaseInsensitiveCollision(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Bool("debug", false, "debug output")
+	f.Bool("Debug", false, "other debug output")
+	if err := f.MarkCaseInsensitive("debug"); err == nil {
+		t.Error("expected error marking a flag that collides by case")
+	}
+	if err := f.MarkCaseInsensitive("missing"); err == nil {
+		t.Error("expected error marking an unknown flag")
+	}
+}
+
+func TestEffectiveValue(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Int("workers", 4, "number of workers")
+	f.Bool("debug", false, "debug output")
+	f.Duration("timeout", time.Second, "timeout")
+	if err := f.Parse([]string{"--workers=8"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if v, err := f.EffectiveString("workers"); err != nil || v != "8" {
+		t.Errorf("expected command-line value 8; got %q, %v", v, err)
+	}
+	if v, err := f.EffectiveInt("workers"); err != nil || v != 8 {
+		t.Errorf("expected command-line value 8; got %d, %v", v, err)
+	}
+	if v, err := f.EffectiveBool("debug"); err != nil || v {
+		t.Errorf("expected default value false; got %v, %v", v, err)
+	}
+	if v, err := f.EffectiveDuration("timeout"); err != nil || v != time.Second {
+		t.Errorf("expected default value 1s; got %v, %v", v, err)
+	}
+	if _, err := f.EffectiveString("missing"); err == nil {
+		t.Error("expected error for unknown flag")
+	}
+}
+
+func TestLongFlagNextArg(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	offset := f.Duration("offset", 0, "offset")
+	count := f.Int("count", 0, "count")
+	if err := f.Parse([]string{"--offset", "-5s", "--count", "-3", "extra"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *offset != -5*time.Second {
+		t.Error("offset flag should be -5s, is ", *offset)
+	}
+	if *count != -3 {
+		t.Error("count flag should be -3, is ", *count)
+	}
+	if args := f.Args(); len(args) != 1 || args[0] != "extra" {
+		t.Errorf("expected args [extra], got %v", args)
+	}
+}
+
+func TestMarkNonNegative(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	timeout := f.Duration("timeout", time.Second, "timeout")
+	f.Int("count", 0, "count")
+	if err := f.MarkNonNegative("count"); err == nil {
+		t.Error("expected error marking a non-duration flag")
+	}
+	if err := f.MarkNonNegative("timeout"); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if err := f.Parse([]string{"--timeout", "-5s"}); err == nil {
+		t.Error("expected error for negative duration")
+	}
+	if err := f.Set("timeout", "-1m"); err == nil {
+		t.Error("expected error setting negative duration")
+	}
+	if *timeout != time.Second {
+		t.Error("timeout flag should be unchanged, is ", *timeout)
+	}
+	if err := f.Parse([]string{"--timeout=5s"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *timeout != 5*time.Second {
+		t.Error("timeout flag should be 5s, is ", *timeout)
+	}
+}
+
+func TestSource(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.String("input", "", "input file")
+	f.String("output", "", "output file")
+	f.String("mode", "fast", "mode")
+	if err := f.Parse([]string{"--input=in.txt"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if err := f.Set("output", "out.txt"); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	for name, want := range map[string]Source{
+		"input":  SourceCommandLine,
+		"output": SourceAPI,
+		"mode":   SourceDefault,
+	} {
+		if got, err := f.Source(name); err != nil || got != want {
+			t.Errorf("expected source %v for %s; got %v, %v", want, name, got, err)
+		}
+	}
+	if _, err := f.Source("missing"); err == nil {
+		t.Error("expected error for unknown flag")
+	}
+}
+
+func TestBindPositional(t *testing.T) {
+	newSet := func() (*FlagSet, *string) {
+		f := NewFlagSet("test", ContinueOnError)
+		input := f.String("input", "default.txt", "input file")
+		if err := f.BindPositional(0, "input"); err != nil {
+			t.Fatal("expected no error; got ", err)
+		}
+		return f, input
+	}
+
+	f, input := newSet()
+	if err := f.Parse([]string{"file.txt", "rest"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *input != "file.txt" {
+		t.Errorf("expected input %q, got %q", "file.txt", *input)
+	}
+	if args := f.Args(); len(args) != 1 || args[0] != "rest" {
+		t.Errorf("expected positional to be consumed, args are %v", args)
+	}
+
+	f, input = newSet()
+	if err := f.Parse([]string{"--input=flag.txt", "file.txt"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *input != "flag.txt" {
+		t.Errorf("expected input %q, got %q", "flag.txt", *input)
+	}
+	if args := f.Args(); len(args) != 1 || args[0] != "file.txt" {
+		t.Errorf("expected positional to remain, args are %v", args)
+	}
+
+	f, input = newSet()
+	if err := f.Parse([]string{}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *input != "default.txt" {
+		t.Errorf("expected input %q, got %q", "default.txt", *input)
+	}
+	if f.NFlag() != 0 {
+		t.Error("expected no flags set, got ", f.NFlag())
+	}
+
+	f, input = newSet()
+	if err := f.Parse([]string{"a", "--", "b"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *input != "a" {
+		t.Errorf("expected input %q, got %q", "a", *input)
+	}
+	if args := f.Args(); len(args) != 1 || args[0] != "b" {
+		t.Errorf("expected args [b], got %v", args)
+	}
+	if n := f.ArgsLenAtDash(); n != 0 {
+		t.Errorf("expected ArgsLenAtDash 0, got %d", n)
+	}
+}
+
+func TestGetter(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Bool("bool", true, "")
+	f.Duration("duration", time.Second, "")
+	f.Float32("float32", 1.5, "")
+	f.Float64("float64", 2.5, "")
+	f.Int("int", 1, "")
+	f.Int8("int8", 2, "")
+	f.Int32("int32", 3, "")
+	f.Int64("int64", 4, "")
+	f.String("string", "s", "")
+	f.Uint("uint", 5, "")
+	f.Uint8("uint8", 6, "")
+	f.Uint16("uint16", 7, "")
+	f.Uint32("uint32", 8, "")
+	f.Uint64("uint64", 9, "")
+	f.IP("ip", net.IPv4(127, 0, 0, 1), "")
+	f.IPMask("ipmask", net.IPv4Mask(255, 255, 255, 0), "")
+	f.Bitmask("bitmask", map[string]int{"a": 1}, "")
+	want := map[string]interface{}{
+		"bool":     true,
+		"duration": time.Second,
+		"float32":  float32(1.5),
+		"float64":  float64(2.5),
+		"int":      int(1),
+		"int8":     int8(2),
+		"int32":    int32(3),
+		"int64":    int64(4),
+		"string":   "s",
+		"uint":     uint(5),
+		"uint8":    uint8(6),
+		"uint16":   uint16(7),
+		"uint32":   uint32(8),
+		"uint64":   uint64(9),
+		"ip":       net.IPv4(127, 0, 0, 1),
+		"ipmask":   net.IPv4Mask(255, 255, 255, 0),
+		"bitmask":  int(0),
+	}
+	f.VisitAll(func(flag *Flag) {
+		g, ok := flag.Value.(Getter)
+		if !ok {
+			t.Errorf("%s: value does not implement Getter", flag.Name)
+			return
+		}
+		got := g.Get()
+		if !reflect.DeepEqual(got, want[flag.Name]) {
+			t.Errorf("%s: expected %#v (%T), got %#v (%T)", flag.Name, want[flag.Name], want[flag.Name], got, got)
+		}
+	})
+}
+
+func TestGet(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Bool("bool", true, "")
+	f.Int16("int16", 1, "")
+	f.Count("count", "")
+	f.BoolTristate("tristate", "")
+	f.BytesHex("bytes", []byte{1}, "")
+	f.String("string", "s", "")
+	f.StringEnum("enum", []string{"a", "b"}, "a", "")
+	f.StringSlice("strings", []string{"a"}, "")
+	f.StringArray("array", []string{"a"}, "")
+	f.StringMap("map", map[string]string{"k": "v"}, "")
+	f.IntSlice("ints", []int{1}, "")
+	f.Int64Slice("int64s", []int64{1}, "")
+	f.DurationSlice("durations", []time.Duration{time.Second}, "")
+	f.EndpointSlice("endpoints", nil, "")
+	f.Quantity("quantity", 1, map[string]float64{"k": 1000}, "")
+	f.Time("time", nil, time.Time{}, "")
+	f.URL("url", nil, "")
+	var ttl TTL
+	f.TTLVar(&ttl, "ttl", TTL{}, "")
+	var levels LogLevels
+	f.LogLevelsVar(&levels, "levels", LogLevels{}, map[string]int{"info": 0}, "")
+	c := customValue("x")
+	f.Var(&c, "custom", "")
+
+	want := map[string]interface{}{
+		"bool":      true,
+		"int16":     int16(0),
+		"count":     int(0),
+		"tristate":  (*bool)(nil),
+		"bytes":     []byte(nil),
+		"string":    "",
+		"enum":      "",
+		"strings":   []string(nil),
+		"array":     []string(nil),
+		"map":       map[string]string(nil),
+		"ints":      []int(nil),
+		"int64s":    []int64(nil),
+		"durations": []time.Duration(nil),
+		"endpoints": []Endpoint(nil),
+		"quantity":  float64(0),
+		"time":      time.Time{},
+		"url":       (*url.URL)(nil),
+		"ttl":       TTL{},
+		"levels":    LogLevels{},
+	}
+	for name, w := range want {
+		got, err := f.Get(name)
+		if err != nil {
+			t.Errorf("%s: expected no error; got %v", name, err)
+			continue
+		}
+		if reflect.TypeOf(got) != reflect.TypeOf(w) {
+			t.Errorf("%s: expected a %T; got %T", name, w, got)
+		}
+	}
+	if v, _ := f.Get("string"); v != "s" {
+		t.Errorf("expected %q; got %#v", "s", v)
+	}
+	if _, err := f.Get("custom"); err == nil {
+		t.Error("expected an error for a Value that does not implement Getter")
+	}
+	if _, err := f.Get("missing"); err == nil {
+		t.Error("expected an error for an unknown flag")
+	}
+}
+
+type typedCustomValue struct{ customValue }
+
+func (typedCustomValue) Type() string { return "color" }
+
+func TestType(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Bitmask("bitmask", map[string]int{"a": 1}, "")
+	f.Bool("bool", false, "")
+	f.BoolTristate("tristate", "")
+	f.BytesHex("hex", nil, "")
+	f.Count("count", "")
+	f.Duration("duration", 0, "")
+	f.DurationSlice("durations", nil, "")
+	f.EndpointSlice("endpoints", nil, "")
+	f.Float32("float32", 0, "")
+	f.Float64("float64", 0, "")
+	f.Float64Range("ratio", 0, 1, 0, "")
+	f.Int("int", 0, "")
+	f.Int8("int8", 0, "")
+	f.Int16("int16", 0, "")
+	f.Int32("int32", 0, "")
+	f.Int64("int64", 0, "")
+	f.IntSlice("ints", nil, "")
+	f.Int64Slice("int64s", nil, "")
+	f.IP("ip", nil, "")
+	f.IPMask("ipmask", nil, "")
+	var levels LogLevels
+	f.LogLevelsVar(&levels, "levels", LogLevels{}, map[string]int{"info": 0}, "")
+	f.Quantity("quantity", 0, map[string]float64{"k": 1000}, "")
+	f.String("string", "", "")
+	f.StringEnum("enum", []string{"a"}, "a", "")
+	f.StringArray("array", nil, "")
+	f.StringMap("map", nil, "")
+	f.StringSlice("strings", nil, "")
+	f.Time("time", nil, time.Time{}, "")
+	var ttl TTL
+	f.TTLVar(&ttl, "ttl", TTL{}, "")
+	f.Uint("uint", 0, "")
+	f.Uint8("uint8", 0, "")
+	f.Uint16("uint16", 0, "")
+	f.Uint32("uint32", 0, "")
+	f.Uint64("uint64", 0, "")
+	f.URL("url", nil, "")
+	var n int
+	f.Var(NewMultiValue(newIntValue(0, &n)), "multi", "")
+	c := customValue("x")
+	f.Var(&c, "custom", "")
+	f.Var(&typedCustomValue{"x"}, "color", "")
+
+	want := map[string]string{
+		"bitmask": "bitmask", "bool": "bool", "tristate": "boolTristate", "hex": "bytesHex",
+		"count": "count", "duration": "duration", "durations": "durationSlice",
+		"endpoints": "endpointSlice", "float32": "float32", "float64": "float64",
+		"ratio": "float64", "int": "int", "int8": "int8", "int16": "int16", "int32": "int32",
+		"int64": "int64", "ints": "intSlice", "int64s": "int64Slice", "ip": "ip",
+		"ipmask": "ipMask", "levels": "logLevels", "quantity": "quantity", "string": "string",
+		"enum": "string", "array": "stringArray", "map": "stringMap", "strings": "stringSlice",
+		"time": "time", "ttl": "ttl", "uint": "uint", "uint8": "uint8", "uint16": "uint16",
+		"uint32": "uint32", "uint64": "uint64", "url": "url", "multi": "int",
+		"custom": "value", "color": "color",
+	}
+	f.VisitAll(func(flag *Flag) {
+		if _, ok := flag.Value.(TypedValue); !ok && flag.Name != "custom" {
+			t.Errorf("%s: value does not implement TypedValue", flag.Name)
+		}
+		got, err := f.Type(flag.Name)
+		if err != nil || got != want[flag.Name] {
+			t.Errorf("%s: expected %q; got %q, %v", flag.Name, want[flag.Name], got, err)
+		}
+	})
+	if _, err := f.Type("missing"); err == nil {
+		t.Error("expected an error for an unknown flag")
+	}
+}
+
+func TestTypedValue(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Var(&typedCustomValue{"red"}, "color", "the color to paint")
+	f.Var(&typedCustomValue{"red"}, "fill", "the `shade` to fill with")
+	c := customValue("x")
+	f.Var(&c, "custom", "a custom value")
+	f.Int("count", 0, "a count")
+
+	if typ, err := f.Type("color"); err != nil || typ != "color" {
+		t.Errorf("expected type %q; got %q, %v", "color", typ, err)
+	}
+	want := "" +
+		"  --color color    the color to paint (default custom:red)\n" +
+		"  --count int      a count\n" +
+		"  --custom value   a custom value (default custom:x)\n" +
+		"  --fill shade     the shade to fill with (default custom:red)\n"
+	if got := f.FlagUsages(); got != want {
+		t.Errorf("expected usage:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestExpandEnvValues(t *testing.T) {
+	oldHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", oldHome)
+	os.Setenv("HOME", "/home/gopher")
+
+	f := NewFlagSet("test", ContinueOnError)
+	path := f.String("path", "", "search path")
+	if err := f.Parse([]string{"--path=$HOME/bin"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *path != "$HOME/bin" {
+		t.Errorf("expected literal value when expansion is off, got %q", *path)
+	}
+
+	f.SetExpandEnvValues(true)
+	if err := f.Parse([]string{"--path=${HOME}/bin"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *path != "/home/gopher/bin" {
+		t.Errorf("expected expanded value, got %q", *path)
+	}
+	if err := f.Set("path", "$HOME/lib"); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *path != "/home/gopher/lib" {
+		t.Errorf("expected expanded value from Set, got %q", *path)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Bool("zeta", false, "")
+	f.Int("alpha", 0, "")
+	f.String("mu", "", "")
+	flags := f.Flags()
+	var names []string
+	for _, flag := range flags {
+		names = append(names, flag.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"alpha", "mu", "zeta"}) {
+		t.Errorf("expected sorted flags [alpha mu zeta], got %v", names)
+	}
+	for _, flag := range flags {
+		if f.Lookup(flag.Name) != flag {
+			t.Errorf("Flags returned %s which is not the defined flag", flag.Name)
+		}
+	}
+}
+
+func TestPassthroughAfter(t *testing.T) {
+	parent := NewFlagSet("parent", ContinueOnError)
+	verbose := parent.BoolP("verbose", "v", false, "verbose output")
+	plugin := parent.String("plugin", "", "plugin to run")
+	parentName := parent.String("name", "", "parent name")
+
+	child := NewFlagSet("child", ContinueOnError)
+	childName := child.String("name", "", "child name")
+	childVerbose := child.BoolP("verbose", "v", false, "child verbose output")
+
+	if err := parent.PassthroughAfter("plugin", child); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	args := []string{"-v", "--plugin", "lint", "--name=x", "-v", "file.go"}
+	if err := parent.Parse(args); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if !*verbose || *plugin != "lint" {
+		t.Errorf("expected parent flags verbose=true plugin=lint, got %v %q", *verbose, *plugin)
+	}
+	if *parentName != "" || parent.NArg() != 0 {
+		t.Errorf("expected parent to stop parsing at plugin, got name=%q args=%v", *parentName, parent.Args())
+	}
+	if *childName != "x" || !*childVerbose {
+		t.Errorf("expected child flags name=x verbose=true, got %q %v", *childName, *childVerbose)
+	}
+	if a := child.Args(); len(a) != 1 || a[0] != "file.go" {
+		t.Errorf("expected child args [file.go], got %v", a)
+	}
+}
+
+// Inputs found while fuzzing the parser; each must fail cleanly.
+func TestMalformedArgs(t *testing.T) {
+	inputs := [][]string{
+		{"-="},
+		{"--="},
+		{"---x"},
+		{"-b="},
+		{"-vb=maybe"},
+		{"-b-"},
+		{"-s"},
+		{"--int"},
+	}
+	for _, args := range inputs {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		f.Usage = func() {}
+		f.BoolP("bool", "b", false, "bool value")
+		f.BoolP("verbose", "v", false, "bool value")
+		f.StringP("string", "s", "", "string value")
+		f.Int("int", 0, "int value")
+		if err := f.Parse(args); err == nil {
+			t.Errorf("expected error parsing %q", args)
+		}
+	}
+}
+
+func TestChangedEmptyString(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	name := f.String("name", "gopher", "a name")
+	if err := f.Parse([]string{"--name="}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if !f.Changed("name") {
+		t.Error("expected --name= to mark the flag as changed")
+	}
+	if *name != "" {
+		t.Errorf("expected empty name, got %q", *name)
+	}
+
+	f = NewFlagSet("test", ContinueOnError)
+	name = f.String("name", "gopher", "a name")
+	if err := f.Parse([]string{}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if f.Changed("name") {
+		t.Error("expected unset flag not to be changed")
+	}
+	if *name != "gopher" {
+		t.Errorf("expected default name, got %q", *name)
+	}
+}
+
+func TestResetForTesting(t *testing.T) {
+	ResetForTesting(func() { t.Error("bad parse") })
+	Bool("before-reset", false, "")
+	ResetForTesting(func() {})
+	if Lookup("before-reset") != nil {
+		t.Error("expected flags defined before ResetForTesting to be gone")
+	}
+	if len(Flags()) != 0 {
+		t.Errorf("expected no flags after ResetForTesting, got %d", len(Flags()))
+	}
+	if err := GetCommandLine().Parse([]string{"arg", "--after"}); err == nil {
+		t.Error("expected interspersed --after to be parsed as an unknown flag")
+	}
+
+	ResetForTesting(nil)
+	if reflect.ValueOf(Usage).Pointer() != reflect.ValueOf(commandLineUsage).Pointer() {
+		t.Error("expected ResetForTesting(nil) to restore the default Usage")
+	}
+}
+
+func TestMissingArgumentAtEnd(t *testing.T) {
+	for _, args := range [][]string{{"-o"}, {"-vo"}, {"--out"}, {"-v", "--out"}} {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		f.BoolP("verbose", "v", false, "verbose output")
+		out := f.StringP("out", "o", "default", "output file")
+		err := f.Parse(args)
+		if err == nil || !strings.Contains(err.Error(), "flag needs an argument") {
+			t.Errorf("%q: expected \"flag needs an argument\" error, got %v", args, err)
+		}
+		if *out != "default" {
+			t.Errorf("%q: expected out to be unchanged, got %q", args, *out)
+		}
+	}
+}
+
+func TestVisitAfterParse(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.BoolP("all", "a", false, "")
+	f.BoolP("brief", "b", false, "")
+	f.BoolP("color", "c", false, "")
+	f.StringP("name", "n", "", "")
+	f.Int("count", 0, "")
+	f.Bool("unused", false, "")
+	args := []string{"--count=3", "-ab", "-n", "x", "arg"}
+	if err := f.Parse(args); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	var visited []string
+	f.Visit(func(flag *Flag) { visited = append(visited, flag.Name) })
+	want := []string{"all", "brief", "count", "name"}
+	if !reflect.DeepEqual(visited, want) {
+		t.Errorf("expected Visit to see %v, got %v", want, visited)
+	}
+	if f.NFlag() != len(want) {
+		t.Errorf("expected NFlag %d, got %d", len(want), f.NFlag())
+	}
+}
+
+func TestShorthandUsages(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.BoolP("verbose", "v", false, "verbose output")
+	f.StringP("output", "o", "", "output `file`")
+	f.IntP("count", "c", 0, "number of runs")
+	f.Bool("long-only", false, "no shorthand")
+	f.BoolP("", "x", false, "extract")
+	want := "  -c  --count\tnumber of runs\n" +
+		"  -o  --output\toutput file\n" +
+		"  -v  --verbose\tverbose output\n" +
+		"  -x\textract\n"
+	if got := f.ShorthandUsages(); got != want {
+		t.Errorf("expected\n%q\ngot\n%q", want, got)
+	}
+}
+
+func TestRedefinePolicy(t *testing.T) {
+	newSet := func(policy RedefinePolicy) *FlagSet {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		f.SetRedefinePolicy(policy)
+		f.StringP("name", "n", "first", "first definition")
+		return f
+	}
+	mustPanic := func(policy RedefinePolicy, define func(f *FlagSet)) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("policy %d: expected panic", policy)
+			}
+		}()
+		define(newSet(policy))
+	}
+
+	// RedefinePanic
+	mustPanic(RedefinePanic, func(f *FlagSet) { f.String("name", "second", "") })
+	mustPanic(RedefinePanic, func(f *FlagSet) { f.StringP("other", "n", "", "") })
+
+	// RedefineError
+	f := newSet(RedefineError)
+	if err := f.VarPE(newStringValue("second", new(string)), "name", "", ""); err == nil {
+		t.Error("RedefineError: expected error for duplicate name")
+	}
+	if err := f.VarPE(newStringValue("", new(string)), "other", "n", ""); err == nil {
+		t.Error("RedefineError: expected error for reused shorthand")
+	}
+	f.String("name", "second", "")
+	if f.Lookup("name").DefValue != "first" || f.Lookup("other") != nil {
+		t.Error("RedefineError: expected the first definition to remain")
+	}
+
+	// RedefineIgnore
+	f = newSet(RedefineIgnore)
+	f.String("name", "second", "")
+	f.StringP("other", "n", "", "")
+	if f.Lookup("name").DefValue != "first" {
+		t.Error("RedefineIgnore: expected the first definition to remain")
+	}
+	if other := f.Lookup("other"); other == nil || other.Shorthand != "" {
+		t.Error("RedefineIgnore: expected other to be defined without a shorthand")
+	}
+	if err := f.Parse([]string{"-n", "x"}); err != nil || f.Lookup("name").Value.String() != "x" {
+		t.Error("RedefineIgnore: expected -n to still set name")
+	}
+
+	// RedefineReplace
+	f = newSet(RedefineReplace)
+	f.StringP("name", "m", "second", "")
+	if f.Lookup("name").DefValue != "second" {
+		t.Error("RedefineReplace: expected the second definition to win")
+	}
+	f.StringP("other", "m", "", "")
+	if f.Lookup("name").Shorthand != "" {
+		t.Error("RedefineReplace: expected name to lose its shorthand")
+	}
+	if err := f.Parse([]string{"-m", "x", "-n", "y"}); err == nil {
+		t.Error("RedefineReplace: expected the freed shorthand -n to be unknown")
+	}
+	if f.Lookup("other").Value.String() != "x" {
+		t.Error("RedefineReplace: expected -m to set other")
+	}
+}
+
+func TestParseDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pflag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"host":    "example.com\n",
+		"port":    " 8080 ",
+		"user":    "from-file",
+		"unknown": "ignored",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f := NewFlagSet("test", ContinueOnError)
+	host := f.String("host", "localhost", "")
+	port := f.Int("port", 80, "")
+	user := f.String("user", "nobody", "")
+	debug := f.Bool("debug", false, "")
+	if err := f.Parse([]string{"--user=from-cmdline"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if err := f.ParseDir(dir); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *host != "example.com" || *port != 8080 {
+		t.Errorf("expected values from files, got host=%q port=%d", *host, *port)
+	}
+	if *user != "from-cmdline" {
+		t.Errorf("expected command line to win, got user=%q", *user)
+	}
+	if *debug || f.Changed("debug") {
+		t.Error("expected flag without a file to be untouched")
+	}
+	if src, _ := f.Source("host"); src != SourceConfig {
+		t.Errorf("expected source %v, got %v", SourceConfig, src)
+	}
+	if f.Changed("host") {
+		t.Error("expected a value from a file not to count as changed")
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "tag"), []byte("a,b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g := NewFlagSet("test", ContinueOnError)
+	g.SetOutput(ioutil.Discard)
+	tags := g.StringSlice("tag", nil, "")
+	g.String("user", "", "")
+	g.MarkRequired("user")
+	if err := g.ParseDir(dir); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if err := g.Parse([]string{"--tag=z", "--user=x"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if !reflect.DeepEqual(*tags, []string{"z"}) {
+		t.Errorf("expected the command line to replace the slice from the file, got %v", *tags)
+	}
+	g.Reset()
+	if err := g.ParseDir(dir); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if err := g.Parse(nil); err == nil {
+		t.Error("expected a value from a file not to satisfy MarkRequired")
+	}
+}
+
+func TestTypedSetters(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	count := f.Int("count", 1, "")
+	verbose := f.Bool("verbose", false, "")
+	name := f.String("name", "", "")
+	timeout := f.Duration("timeout", 0, "")
+	size := f.Uint64("size", 0, "")
+	ch := make(chan FlagChange, 5)
+	f.SetChangeChannel(ch)
+
+	if err := f.SetInt("count", 5); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if *count != 5 || !f.Changed("count") || f.Lookup("count").Source != SourceAPI {
+		t.Errorf("expected count=5, changed and set by the API; got %d, %v, %v", *count, f.Changed("count"), f.Lookup("count").Source)
+	}
+	if c := <-ch; c.Name != "count" || c.Value != "5" {
+		t.Errorf("expected a change notification for count; got %+v", c)
+	}
+	if err := f.SetBool("verbose", true); err != nil || !*verbose {
+		t.Errorf("SetBool: got %v, %v", *verbose, err)
+	}
+	if err := f.SetString("name", "gopher"); err != nil || *name != "gopher" {
+		t.Errorf("SetString: got %q, %v", *name, err)
+	}
+	if err := f.SetDuration("timeout", time.Second); err != nil || *timeout != time.Second {
+		t.Errorf("SetDuration: got %v, %v", *timeout, err)
+	}
+	if err := f.SetUint64("size", 1<<40); err != nil || *size != 1<<40 {
+		t.Errorf("SetUint64: got %v, %v", *size, err)
+	}
+
+	if err := f.SetInt("name", 1); err == nil || err.Error() != "flag -name is not an int flag" {
+		t.Errorf("expected a type mismatch error; got %v", err)
+	}
+	if err := f.SetInt64("count", 1); err == nil {
+		t.Error("expected an error setting an int flag as an int64")
+	}
+	if *count != 5 {
+		t.Errorf("expected count to be unchanged by a failed set; got %d", *count)
+	}
+	if err := f.SetBool("missing", true); err == nil {
+		t.Error("expected an error for an unknown flag")
+	}
+	f.MarkNonNegative("timeout")
+	if err := f.SetDuration("timeout", -time.Second); err == nil {
+		t.Error("expected an error setting a non-negative duration flag below zero")
+	}
+}
+
+func TestTypedGetters(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.String("string", "", "")
+	f.Int("int", 0, "")
+	f.Bool("bool", false, "")
+	f.Int64("int64", 0, "")
+	f.Uint("uint", 0, "")
+	f.Uint64("uint64", 0, "")
+	f.Float64("float64", 0, "")
+	f.Duration("duration", 0, "")
+	args := []string{
+		"--string=hello",
+		"--int=-1",
+		"--bool",
+		"--int64=1099511627776",
+		"--uint=2",
+		"--uint64=3",
+		"--float64=1.5",
+		"--duration=2m",
+	}
+	if err := f.Parse(args); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if v, err := f.GetString("string"); err != nil || v != "hello" {
+		t.Errorf("GetString: got %q, %v", v, err)
+	}
+	if v, err := f.GetInt("int"); err != nil || v != -1 {
+		t.Errorf("GetInt: got %d, %v", v, err)
+	}
+	if v, err := f.GetBool("bool"); err != nil || !v {
+		t.Errorf("GetBool: got %v, %v", v, err)
+	}
+	if v, err := f.GetInt64("int64"); err != nil || v != 1<<40 {
+		t.Errorf("GetInt64: got %d, %v", v, err)
+	}
+	if v, err := f.GetUint("uint"); err != nil || v != 2 {
+		t.Errorf("GetUint: got %d, %v", v, err)
+	}
+	if v, err := f.GetUint64("uint64"); err != nil || v != 3 {
+		t.Errorf("GetUint64: got %d, %v", v, err)
+	}
+	if v, err := f.GetFloat64("float64"); err != nil || v != 1.5 {
+		t.Errorf("GetFloat64: got %v, %v", v, err)
+	}
+	if v, err := f.GetDuration("duration"); err != nil || v != 2*time.Minute {
+		t.Errorf("GetDuration: got %v, %v", v, err)
+	}
+	if _, err := f.GetInt("string"); err == nil {
+		t.Error("expected error getting a string flag as an int")
+	}
+	if _, err := f.GetString("missing"); err == nil {
+		t.Error("expected error getting an unknown flag")
+	}
+}
+
+func TestMarkRequiredOneOf(t *testing.T) {
+	newSet := func(exclusive bool) *FlagSet {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		f.String("file", "", "")
+		f.String("url", "", "")
+		f.Bool("stdin", false, "")
+		if err := f.MarkRequiredOneOf("file", "url", "stdin"); err != nil {
+			t.Fatal("expected no error; got ", err)
+		}
+		if exclusive {
+			if err := f.MarkMutuallyExclusive("file", "url", "stdin"); err != nil {
+				t.Fatal("expected no error; got ", err)
+			}
+		}
+		return f
+	}
+
+	err := newSet(false).Parse([]string{})
+	if err == nil || !strings.Contains(err.Error(), "[file url stdin]") {
+		t.Errorf("expected error listing the group, got %v", err)
+	}
+	if err := newSet(false).Parse([]string{"--url=x"}); err != nil {
+		t.Errorf("expected no error with one set; got %v", err)
+	}
+	if err := newSet(false).Parse([]string{"--url=x", "--stdin"}); err != nil {
+		t.Errorf("expected no error with two set; got %v", err)
+	}
+	if err := newSet(true).Parse([]string{"--url=x", "--stdin"}); err == nil {
+		t.Error("expected error with two set in an exclusive group")
+	}
+	if err := newSet(true).Parse([]string{"--file=x"}); err != nil {
+		t.Errorf("expected no error with exactly one set; got %v", err)
+	}
+	if err := newSet(false).MarkRequiredOneOf("file", "missing"); err == nil {
+		t.Error("expected error for unknown flag in group")
+	}
+}
+
+func TestInterspersed(t *testing.T) {
+	tests := []struct {
+		interspersed bool
+		verbose      bool
+		args         []string
+	}{
+		{true, true, []string{"file.txt", "other.txt"}},
+		{false, false, []string{"file.txt", "--verbose", "other.txt"}},
+	}
+	for _, tt := range tests {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetInterspersed(tt.interspersed)
+		verbose := f.Bool("verbose", false, "")
+		if err := f.Parse([]string{"file.txt", "--verbose", "other.txt"}); err != nil {
+			t.Fatal("expected no error; got ", err)
+		}
+		if *verbose != tt.verbose {
+			t.Errorf("interspersed=%v: expected verbose %v, got %v", tt.interspersed, tt.verbose, *verbose)
+		}
+		if !reflect.DeepEqual(f.Args(), tt.args) {
+			t.Errorf("interspersed=%v: expected args %v, got %v", tt.interspersed, tt.args, f.Args())
+		}
+	}
+}
+
+func TestNormalizeFunc(t *testing.T) {
+	wordSep := func(f *FlagSet, name string) NormalizedName {
+		return NormalizedName(strings.Replace(name, "_", "-", -1))
+	}
+	f := NewFlagSet("test", ContinueOnError)
+	before := f.Bool("defined_before", false, "")
+	f.SetNormalizeFunc(wordSep)
+	after := f.String("defined_after", "", "")
+	if err := f.Parse([]string{"--defined-before", "--defined_after=x"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if !*before || *after != "x" {
+		t.Errorf("expected both flags set, got %v %q", *before, *after)
+	}
+	if flag := f.Lookup("defined_after"); flag == nil || flag.Name != "defined-after" {
+		t.Errorf("expected flag stored under its normalized name, got %v", flag)
+	}
+	if err := f.Set("defined_before", "false"); err != nil || *before {
+		t.Errorf("expected Set to find the normalized flag, got %v", err)
+	}
+	if f.GetNormalizeFunc()(f, "a_b") != "a-b" {
+		t.Error("expected GetNormalizeFunc to return the installed function")
+	}
+	if NewFlagSet("test", ContinueOnError).GetNormalizeFunc()(f, "a_b") != "a_b" {
+		t.Error("expected the default normalization to leave names unchanged")
+	}
+}
+
+func TestNormalizedNameAccess(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetNormalizeFunc(func(f *FlagSet, name string) NormalizedName {
+		return NormalizedName(strings.Replace(name, "_", "-", -1))
+	})
+	count := f.Int("max_count", 1, "")
+	if err := f.Set("max-count", "5"); err != nil {
+		t.Fatal("expected Set with the dashed name to succeed; got", err)
+	}
+	if *count != 5 {
+		t.Errorf("expected 5; got %d", *count)
+	}
+	for _, name := range []string{"max_count", "max-count"} {
+		if !f.Changed(name) {
+			t.Errorf("expected Changed(%q) to be true", name)
+		}
+		if v, err := f.GetInt(name); err != nil || v != 5 {
+			t.Errorf("GetInt(%q): got %v, %v", name, v, err)
+		}
+	}
+	if err := f.Restore(map[string]string{"max_count": "2"}); err != nil || *count != 2 {
+		t.Errorf("expected Restore to find the normalized flag; got %v, %d", err, *count)
+	}
+}
+
+func TestChangeChannel(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	f.BoolP("verbose", "v", false, "")
+	f.Int("count", 0, "")
+	ch := make(chan FlagChange, 3)
+	f.SetChangeChannel(ch)
+	if err := f.Parse([]string{"-v", "--count=3"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if err := f.Set("count", "4"); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	f.Set("count", "x") // fails, so nothing is sent
+	f.Set("count", "5") // channel is full, so this change is dropped
+	close(ch)
+	var got []FlagChange
+	for c := range ch {
+		got = append(got, c)
+	}
+	want := []FlagChange{{"verbose", "true"}, {"count", "3"}, {"count", "4"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected changes %v, got %v", want, got)
+	}
+}
+
+func TestDiffDefaults(t *testing.T) {
+	prod := NewFlagSet("prod", ContinueOnError)
+	prod.Int("workers", 16, "")
+	prod.String("host", "0.0.0.0", "")
+	prod.Bool("prod-only", true, "")
+	dev := NewFlagSet("dev", ContinueOnError)
+	dev.Int("workers", 2, "")
+	dev.String("host", "0.0.0.0", "")
+	dev.Bool("dev-only", true, "")
+	want := map[string][2]string{"workers": {"16", "2"}}
+	if got := prod.DiffDefaults(dev); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestMarkDeprecated(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(&buf)
+	old := f.String("old", "", "old flag")
+	f.String("new", "", "new flag")
+	if err := f.MarkDeprecated("old", ""); err == nil {
+		t.Error("expected error for empty message")
+	}
+	if err := f.MarkDeprecated("missing", "use --new instead"); err == nil {
+		t.Error("expected error for unknown flag")
+	}
+	if err := f.MarkDeprecated("old", "use --new instead"); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if err := f.Parse([]string{"--old=a", "--old=b", "--new=c"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *old != "b" {
+		t.Errorf("expected deprecated flag to still be set, got %q", *old)
+	}
+	warning := "Flag --old has been deprecated, use --new instead\n"
+	if got := buf.String(); got != warning {
+		t.Errorf("expected a single warning %q, got %q", warning, got)
+	}
+
+	buf.Reset()
+	f.PrintDefaults()
+	if out := buf.String(); strings.Contains(out, "--old") || !strings.Contains(out, "--new") {
+		t.Errorf("expected deprecated flag to be hidden from usage, got %q", out)
+	}
+}
+
+func TestMarkShorthandDeprecated(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(&buf)
+	name := f.StringP("name", "n", "", "a name")
+	f.BoolP("verbose", "v", false, "verbose output")
+	f.Bool("long", false, "long only")
+	if err := f.MarkShorthandDeprecated("name", ""); err == nil {
+		t.Error("expected error for empty message")
+	}
+	if err := f.MarkShorthandDeprecated("long", "gone"); err == nil {
+		t.Error("expected error for a flag without a shorthand")
+	}
+	if err := f.MarkShorthandDeprecated("name", "use --name instead"); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+
+	if err := f.Parse([]string{"--name=a", "-v"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if got := buf.String(); got != "" {
+		t.Errorf("expected the long form to be silent, got %q", got)
+	}
+	if err := f.Parse([]string{"-n", "b", "-vn", "c"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *name != "c" {
+		t.Errorf("expected the shorthand to still set the flag, got %q", *name)
+	}
+	warning := "Flag shorthand -n has been deprecated, use --name instead\n"
+	if got := buf.String(); got != warning {
+		t.Errorf("expected a single warning %q, got %q", warning, got)
+	}
+
+	want := "" +
+		"      --long          long only\n" +
+		"      --name string   a name\n" +
+		"  -v, --verbose       verbose output\n"
+	if got := f.FlagUsages(); got != want {
+		t.Errorf("expected usage:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestHelpGroup(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(&buf)
+	f.Usage = func() { defaultUsage(f) }
+	f.String("host", "", "server host")
+	f.Int("port", 0, "server port")
+	f.Bool("verbose", false, "verbose output")
+	f.SetFlagGroup("host", "network")
+	f.SetFlagGroup("port", "network")
+
+	if err := f.Parse([]string{"--help"}); err != ErrHelp {
+		t.Fatal("expected ErrHelp; got ", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "--host") || !strings.Contains(out, "--verbose") {
+		t.Errorf("expected full help to list every flag, got %q", out)
+	}
+
+	buf.Reset()
+	if err := f.Parse([]string{"--help=network"}); err != ErrHelp {
+		t.Fatal("expected ErrHelp; got ", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "Usage of test (network):\n") {
+		t.Errorf("expected group header, got %q", out)
+	}
+	if !strings.Contains(out, "--host") || !strings.Contains(out, "--port") || strings.Contains(out, "--verbose") {
+		t.Errorf("expected only network flags, got %q", out)
+	}
+
+	if err := f.Parse([]string{"--help=nosuchgroup"}); err == nil || err == ErrHelp {
+		t.Error("expected error for unknown help group; got ", err)
+	}
+}
+
+func TestUnquoteUsage(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Int("workers", 4, "set the `number` of workers")
+	f.String("odd", "", "a single ` back quote")
+	f.Bool("bool", false, "")
+	f.Count("count", "")
+	f.Duration("duration", 0, "")
+	f.Float32("float32", 0, "")
+	f.Float64Range("ratio", 0, 1, 0, "")
+	f.Int16("int16", 0, "")
+	f.Uint8("uint8", 0, "")
+	f.StringSlice("strings", nil, "")
+	f.IntSlice("ints", nil, "")
+	f.DurationSlice("durations", nil, "")
+	f.StringMap("map", nil, "")
+	f.IP("ip", nil, "")
+	f.BytesHex("hex", nil, "")
+	f.Time("time", nil, time.Time{}, "")
+	f.URL("url", nil, "")
+	c := customValue("x")
+	f.Var(&c, "custom", "")
+
+	want := map[string]string{
+		"odd":       "string",
+		"bool":      "",
+		"count":     "",
+		"duration":  "duration",
+		"float32":   "float",
+		"ratio":     "float",
+		"int16":     "int",
+		"uint8":     "uint",
+		"strings":   "strings",
+		"ints":      "ints",
+		"durations": "durations",
+		"map":       "key=value",
+		"ip":        "ip",
+		"hex":       "hex",
+		"time":      "time",
+		"url":       "url",
+		"custom":    "value",
+	}
+	for flagName, placeholder := range want {
+		if name, _ := UnquoteUsage(f.Lookup(flagName)); name != placeholder {
+			t.Errorf("%s: expected placeholder %q; got %q", flagName, placeholder, name)
+		}
+	}
+
+	name, usage := UnquoteUsage(f.Lookup("workers"))
+	if name != "number" || usage != "set the number of workers" {
+		t.Errorf("expected (%q, %q); got (%q, %q)", "number", "set the number of workers", name, usage)
+	}
+	if got := f.FlagUsages(); !strings.Contains(got, "  --workers number ") {
+		t.Errorf("expected the back-quoted placeholder in the usage message; got %q", got)
+	}
+}
+
+func TestFlagColumnWidth(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	if w := f.FlagColumnWidth(); w != 0 {
+		t.Errorf("expected 0 for no flags; got %d", w)
+	}
+	f.Bool("v", false, "verbose")
+	if w := f.FlagColumnWidth(); w != len("  --v") {
+		t.Errorf("expected %d; got %d", len("  --v"), w)
+	}
+	f.Duration("timeout", 0, "timeout")
+	if w := f.FlagColumnWidth(); w != len("  --timeout duration") {
+		t.Errorf("expected %d; got %d", len("  --timeout duration"), w)
+	}
+	f.StringP("name", "n", "", "a `label` to use")
+	if w := f.FlagColumnWidth(); w != len("      --timeout duration") {
+		t.Errorf("expected %d; got %d", len("      --timeout duration"), w)
+	}
+	f.String("a-very-long-flag-name", "", "")
+	f.MarkDeprecated("a-very-long-flag-name", "gone")
+	width := f.FlagColumnWidth()
+	if width != len("      --timeout duration") {
+		t.Errorf("expected deprecated flags to be left out; got %d", width)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(f.FlagUsages(), "\n"), "\n") {
+		if len(line) < width+3 || line[width+2] != ' ' || line[width+3] == ' ' {
+			t.Errorf("expected usage text to start at column %d; got %q", width+3, line)
+		}
+	}
+}
+
+func TestFlagUsagesGroups(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Int("port", 80, "server port")
+	f.BoolP("verbose", "v", false, "verbose output")
+	f.String("format", "text", "output format")
+	f.StringP("host", "H", "", "server host")
+	f.Bool("ipv6", false, "use IPv6")
+	f.SetAnnotation("port", "group", []string{"network"})
+	f.SetAnnotation("format", "group", []string{"output"})
+	f.SetFlagGroup("host", "network")
+	f.SetFlagGroup("ipv6", "network")
+
+	want := "" +
+		"  -v, --verbose   verbose output\n" +
+		"\n" +
+		"network:\n" +
+		"  -H, --host string   server host\n" +
+		"      --ipv6          use IPv6\n" +
+		"      --port int      server port (default 80)\n" +
+		"\n" +
+		"output:\n" +
+		"      --format string   output format (default \"text\")\n"
+	if got := f.FlagUsages(); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+	if group, _, _ := f.GetAnnotation("host", GroupAnnotation); !reflect.DeepEqual(group, []string{"network"}) {
+		t.Errorf("expected SetFlagGroup to set the group annotation; got %v", group)
+	}
+}
+
+func TestFlagUsages(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.BoolP("verbose", "v", false, "verbose output")
+	f.String("name", "gopher", "a `name` to greet")
+	f.Int("count", 3, "number of greetings")
+	f.StringSlice("tags", nil, "tags")
+	f.StringMap("labels", nil, "labels")
+	want := "" +
+		"      --count int          number of greetings (default 3)\n" +
+		"      --labels key=value   labels\n" +
+		"      --name name          a name to greet (default \"gopher\")\n" +
+		"      --tags strings       tags\n" +
+		"  -v, --verbose            verbose output\n"
+	if got := f.FlagUsages(); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+	var buf bytes.Buffer
+	f.SetOutput(&buf)
+	f.PrintDefaults()
+	if buf.String() != want {
+		t.Errorf("expected PrintDefaults to print FlagUsages, got\n%s", buf.String())
+	}
+}
+
+func TestFlagUsagesWrapped(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Bool("verbose", false, "print a great deal of detail about what is happening while the program runs")
+	want := "" +
+		"  --verbose   print a great deal of detail about what\n" +
+		"              is happening while the program runs\n"
+	if got := f.FlagUsagesWrapped(54); got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(f.FlagUsagesWrapped(54), "\n"), "\n") {
+		if len(line) > 54 {
+			t.Errorf("line longer than 54 columns: %q", line)
+		}
+	}
+	if got := f.FlagUsagesWrapped(0); strings.Count(got, "\n") != 1 {
+		t.Errorf("expected no wrapping without a width, got\n%s", got)
+	}
+}
+
+func TestAddFlagSet(t *testing.T) {
+	common := NewFlagSet("common", ContinueOnError)
+	verbose := common.BoolP("verbose", "v", false, "verbose output")
+	config := common.StringP("config", "c", "", "config file")
+	commonName := common.String("name", "common", "")
+
+	cmd := NewFlagSet("cmd", ContinueOnError)
+	name := cmd.String("name", "cmd", "")
+	count := cmd.IntP("count", "c", 0, "")
+
+	cmd.AddFlagSet(common)
+	args := []string{"-v", "--config=x.yaml", "--name=n", "-c", "3"}
+	if err := cmd.Parse(args); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if !*verbose || *config != "x.yaml" {
+		t.Errorf("expected merged flags to be set, got verbose=%v config=%q", *verbose, *config)
+	}
+	if *name != "n" || *commonName != "common" {
+		t.Errorf("expected existing flag to be kept, got name=%q common name=%q", *name, *commonName)
+	}
+	if *count != 3 {
+		t.Errorf("expected -c to keep meaning --count, got %d", *count)
+	}
+	if cmd.Lookup("config").Shorthand != "" || common.Lookup("config").Shorthand != "c" {
+		t.Error("expected config to be added without its clashing shorthand")
+	}
+
+	common.SetFlagGroup("verbose", "output")
+	other := NewFlagSet("other", ContinueOnError)
+	other.AddFlagSet(common)
+	other.Lookup("verbose").Annotations[GroupAnnotation][0] = "changed"
+	other.SetAnnotation("verbose", "hint", []string{"x"})
+	if group, _, _ := common.GetAnnotation("verbose", GroupAnnotation); !reflect.DeepEqual(group, []string{"output"}) {
+		t.Errorf("expected the source set's annotations to be left alone, got %v", group)
+	}
+	if _, ok, _ := common.GetAnnotation("verbose", "hint"); ok {
+		t.Error("expected an annotation added after merging not to reach the source set")
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	name := f.String("name", "bob", "")
+	count := f.Int("count", 1, "")
+	tags := f.StringSlice("tag", []string{"a"}, "")
+	labels := f.StringMap("label", map[string]string{"k": "v"}, "")
+	if err := f.Parse([]string{"--count=2"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	snap := f.Snapshot()
+	if snap["count"] != "2" || snap["tag"] != "[a]" {
+		t.Errorf("unexpected snapshot %v", snap)
+	}
+	if snap["-count"] != "set from command line" || snap["-name"] != "default" {
+		t.Errorf("unexpected flag states in snapshot %v", snap)
+	}
+
+	args := []string{"--name=alice", "--count=5", "--tag=b,c", "--label=x=y"}
+	if err := f.Parse(args); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if err := f.Restore(snap); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *name != "bob" || *count != 2 {
+		t.Errorf("expected bob and 2 after restore, got %q and %d", *name, *count)
+	}
+	if !reflect.DeepEqual(*tags, []string{"a"}) {
+		t.Errorf("expected tags [a] after restore, got %v", *tags)
+	}
+	if !reflect.DeepEqual(*labels, map[string]string{"k": "v"}) {
+		t.Errorf("expected labels map[k:v] after restore, got %v", *labels)
+	}
+	if f.Changed("name") || f.Changed("tag") || f.Changed("label") || !f.Changed("count") || f.NFlag() != 1 {
+		t.Errorf("expected only count to be changed after restore, got %d flags set", f.NFlag())
+	}
+	if source, _ := f.Source("name"); source != SourceDefault {
+		t.Errorf("expected name's source to be default after restore, got %v", source)
+	}
+
+	f.Reset()
+	if err := f.Restore(snap); err != nil || !f.Changed("count") {
+		t.Errorf("expected Restore to mark count as set again; got %v", err)
+	}
+	if source, _ := f.Source("count"); source != SourceCommandLine {
+		t.Errorf("expected count's source to be the command line after restore, got %v", source)
+	}
+	if err := f.Restore(map[string]string{"-count": "nowhere"}); err == nil {
+		t.Error("expected error restoring an invalid state")
+	}
+
+	if err := f.Restore(map[string]string{"missing": "x"}); err == nil {
+		t.Error("expected error restoring unknown flag")
+	}
+	if err := f.Restore(map[string]string{"count": "x"}); err == nil {
+		t.Error("expected error restoring invalid value")
+	}
+}
+
+func TestMarkRequired(t *testing.T) {
+	newSet := func() *FlagSet {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		f.String("user", "", "")
+		f.String("host", "", "")
+		f.Int("port", 22, "")
+		for _, name := range []string{"user", "host"} {
+			if err := f.MarkRequired(name); err != nil {
+				t.Fatal("expected no error; got ", err)
+			}
+		}
+		return f
+	}
+
+	err := newSet().Parse([]string{"--port=2222"})
+	if err == nil || !strings.Contains(err.Error(), "[host user]") {
+		t.Errorf("expected error naming both missing flags, got %v", err)
+	}
+	err = newSet().Parse([]string{"--user=root"})
+	if err == nil || !strings.Contains(err.Error(), "[host]") {
+		t.Errorf("expected error naming host, got %v", err)
+	}
+	if err := newSet().Parse([]string{"--user=root", "--host=example.com"}); err != nil {
+		t.Errorf("expected no error with required flags set; got %v", err)
+	}
+	if err := newSet().MarkRequired("missing"); err == nil {
+		t.Error("expected error marking unknown flag required")
+	}
+}
+
+func TestShorthandValidation(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	for _, shorthand := range []string{"=", "-", "\x01", " "} {
+		if err := f.VarPE(newBoolValue(false, new(bool)), "flag", shorthand, ""); err == nil {
+			t.Errorf("expected error for shorthand %q", shorthand)
+		}
+	}
+	if f.Lookup("flag") != nil {
+		t.Error("expected rejected flag not to be defined")
+	}
+	for _, shorthand := range []string{"a", "Z", "9"} {
+		name := "flag" + shorthand
+		if err := f.VarPE(newBoolValue(false, new(bool)), name, shorthand, ""); err != nil {
+			t.Errorf("expected no error for shorthand %q; got %v", shorthand, err)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected BoolP to panic on an invalid shorthand")
+		}
+	}()
+	f.BoolP("equals", "=", false, "")
+}
+
+func TestBindEnv(t *testing.T) {
+	newSet := func() (*FlagSet, *string, *int) {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		host := f.String("host", "localhost", "")
+		port := f.Int("port", 80, "")
+		if err := f.BindEnv("host", "PFLAG_TEST_HOST"); err != nil {
+			t.Fatal("expected no error; got ", err)
+		}
+		if err := f.BindEnv("port", "PFLAG_TEST_PORT"); err != nil {
+			t.Fatal("expected no error; got ", err)
+		}
+		return f, host, port
+	}
+	defer os.Unsetenv("PFLAG_TEST_HOST")
+	defer os.Unsetenv("PFLAG_TEST_PORT")
+
+	f, host, port := newSet()
+	if err := f.Parse([]string{}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *host != "localhost" || *port != 80 || f.Changed("host") {
+		t.Errorf("expected defaults with no environment, got %q %d", *host, *port)
+	}
+
+	os.Setenv("PFLAG_TEST_HOST", "example.com")
+	f, host, _ = newSet()
+	if s, _ := f.EffectiveString("host"); s != "example.com" {
+		t.Errorf("expected effective value from environment before Parse, got %q", s)
+	}
+	if err := f.Parse([]string{}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *host != "example.com" {
+		t.Errorf("expected environment to beat default, got %q", *host)
+	}
+	if source, _ := f.Source("host"); source != SourceEnv || !f.Changed("host") {
+		t.Errorf("expected host to be set from the environment, got source %v", source)
+	}
+
+	f, host, _ = newSet()
+	if err := f.Parse([]string{"--host=cli.example.com"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *host != "cli.example.com" {
+		t.Errorf("expected command line to beat environment, got %q", *host)
+	}
+
+	os.Setenv("PFLAG_TEST_PORT", "eighty")
+	f, _, _ = newSet()
+	if err := f.Parse([]string{}); err == nil || !strings.Contains(err.Error(), "PFLAG_TEST_PORT") {
+		t.Errorf("expected error naming the environment variable, got %v", err)
+	}
+	if err := f.BindEnv("missing", "X"); err == nil {
+		t.Error("expected error binding unknown flag")
+	}
+}
+
+func TestMarkCommandLineOnly(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pflag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "token"), []byte("from-file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("PFLAG_TEST_TOKEN", "from-env")
+	os.Setenv("PFLAG_TEST_USER", "from-env")
+	defer os.Unsetenv("PFLAG_TEST_TOKEN")
+	defer os.Unsetenv("PFLAG_TEST_USER")
+
+	newSet := func() (*FlagSet, *string, *string) {
+		f := NewFlagSet("test", ContinueOnError)
+		token := f.String("token", "", "")
+		user := f.String("user", "", "")
+		f.BindEnv("token", "PFLAG_TEST_TOKEN")
+		f.BindEnv("user", "PFLAG_TEST_USER")
+		if err := f.MarkCommandLineOnly("token"); err != nil {
+			t.Fatal("expected no error; got ", err)
+		}
+		return f, token, user
+	}
+
+	f, token, user := newSet()
+	if err := f.Parse([]string{}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if err := f.ParseDir(dir); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *token != "" || f.Changed("token") {
+		t.Errorf("expected token to ignore environment and files, got %q", *token)
+	}
+	if s, _ := f.EffectiveString("token"); s != "" {
+		t.Errorf("expected effective token to ignore environment, got %q", s)
+	}
+	if *user != "from-env" {
+		t.Errorf("expected unmarked flag to take the environment value, got %q", *user)
+	}
+
+	f, token, user = newSet()
+	if err := f.Parse([]string{"--token=from-cmdline", "--user=from-cmdline"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *token != "from-cmdline" || *user != "from-cmdline" {
+		t.Errorf("expected command-line values, got token=%q user=%q", *token, *user)
+	}
+	if err := f.MarkCommandLineOnly("missing"); err == nil {
+		t.Error("expected error marking unknown flag")
+	}
+}
+
+func TestNoOptDefVal(t *testing.T) {
+	newSet := func() (*FlagSet, *string) {
+		f := NewFlagSet("test", ContinueOnError)
+		color := f.StringP("color", "c", "auto", "")
+		f.Lookup("color").NoOptDefVal = "always"
+		f.BoolP("verbose", "v", false, "")
+		return f, color
+	}
+	tests := []struct {
+		args  []string
+		color string
+		rest  []string
+	}{
+		{[]string{"--color", "file"}, "always", []string{"file"}},
+		{[]string{"--color=never", "file"}, "never", []string{"file"}},
+		{[]string{"-cv", "file"}, "always", []string{"file"}},
+		{[]string{"-c=never", "file"}, "never", []string{"file"}},
+		{[]string{"-vc=never", "file"}, "never", []string{"file"}},
+		{[]string{"file"}, "auto", []string{"file"}},
+	}
+	for _, test := range tests {
+		f, color := newSet()
+		if err := f.Parse(test.args); err != nil {
+			t.Errorf("%v: expected no error; got %v", test.args, err)
+			continue
+		}
+		if *color != test.color {
+			t.Errorf("%v: expected color %q, got %q", test.args, test.color, *color)
+		}
+		if !reflect.DeepEqual(f.Args(), test.rest) {
+			t.Errorf("%v: expected args %v, got %v", test.args, test.rest, f.Args())
+		}
+	}
+
+	f := NewFlagSet("test", ContinueOnError)
+	verbose := f.BoolP("verbose", "v", true, "")
+	if err := f.Parse([]string{"-v=false"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if *verbose || !f.Changed("verbose") {
+		t.Errorf("expected -v=false to set verbose to false; got %v", *verbose)
+	}
+}
+
+func TestShorthandClusterValues(t *testing.T) {
+	tests := []struct {
+		args    []string
+		verbose bool
+		output  string
+		rest    []string
+	}{
+		{[]string{"-voutput.txt"}, true, "utput.txt", []string{}},
+		{[]string{"-vooutput.txt"}, true, "output.txt", []string{}},
+		{[]string{"-vo", "output.txt", "file"}, true, "output.txt", []string{"file"}},
+		{[]string{"-ov", "file"}, false, "v", []string{"file"}},
+		{[]string{"-o", "-v"}, false, "-v", []string{}},
+	}
+	for _, test := range tests {
+		f := NewFlagSet("test", ContinueOnError)
+		verbose := f.BoolP("verbose", "v", false, "")
+		output := f.StringP("output", "o", "", "")
+		if err := f.Parse(test.args); err != nil {
+			t.Errorf("%v: expected no error; got %v", test.args, err)
+			continue
+		}
+		if *verbose != test.verbose || *output != test.output {
+			t.Errorf("%v: expected verbose=%v output=%q, got verbose=%v output=%q", test.args, test.verbose, test.output, *verbose, *output)
+		}
+		if !reflect.DeepEqual(f.Args(), test.rest) {
+			t.Errorf("%v: expected args %v, got %v", test.args, test.rest, f.Args())
+		}
+	}
+}
+
+func TestSetMatchesParse(t *testing.T) {
+	newSet := func() (*FlagSet, chan FlagChange) {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		f.String("name", "", "")
+		f.Duration("timeout", time.Second, "")
+		f.MarkNonNegative("timeout")
+		changes := make(chan FlagChange, 10)
+		f.SetChangeChannel(changes)
+		return f, changes
+	}
+	state := func(f *FlagSet, changes chan FlagChange) []string {
+		close(changes)
+		var s []string
+		f.Visit(func(flag *Flag) {
+			s = append(s, flag.Name+"="+flag.Value.String())
+		})
+		for c := range changes {
+			s = append(s, "change:"+c.Name+"="+c.Value)
+		}
+		return s
+	}
+
+	parsed, parsedChanges := newSet()
+	if err := parsed.Parse([]string{"--name=x", "--timeout=5s"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	set, setChanges := newSet()
+	if err := set.Set("name", "x"); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if err := set.Set("timeout", "5s"); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	for _, name := range []string{"name", "timeout"} {
+		if parsed.Changed(name) != set.Changed(name) {
+			t.Errorf("%s: Changed differs between Parse and Set", name)
+		}
+	}
+	if p, s := state(parsed, parsedChanges), state(set, setChanges); !reflect.DeepEqual(p, s) {
+		t.Errorf("expected identical state, got %v from Parse and %v from Set", p, s)
+	}
+
+	parsed, _ = newSet()
+	set, _ = newSet()
+	parseErr := parsed.Parse([]string{"--timeout=-5s"})
+	setErr := set.Set("timeout", "-5s")
+	if parseErr == nil || setErr == nil {
+		t.Errorf("expected both paths to reject a negative timeout, got %v and %v", parseErr, setErr)
+	}
+	if parsed.Changed("timeout") || set.Changed("timeout") {
+		t.Error("expected a rejected value not to mark the flag as set")
+	}
+}
+
+func TestPassthroughAfterFirstPositional(t *testing.T) {
+	tests := []struct {
+		args []string
+		rest []string
+	}{
+		{[]string{"-v", "foo", "--bar", "baz"}, []string{"foo", "--bar", "baz"}},
+		{[]string{"-v", "--unknown", "-v", "x"}, []string{"--unknown", "-v", "x"}},
+		{[]string{"-x", "--verbose"}, []string{"-x", "--verbose"}},
+		{[]string{"-v", "--", "-v"}, []string{"-v"}},
+	}
+	for _, test := range tests {
+		f := NewFlagSet("test", ContinueOnError)
+		verbose := f.BoolP("verbose", "v", false, "")
+		f.SetPassthroughAfterFirstPositional(true)
+		if err := f.Parse(test.args); err != nil {
+			t.Errorf("%v: expected no error; got %v", test.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(f.Args(), test.rest) {
+			t.Errorf("%v: expected args %v, got %v", test.args, test.rest, f.Args())
+		}
+		if test.args[0] == "-v" && !*verbose {
+			t.Errorf("%v: expected -v to be parsed", test.args)
+		}
+	}
+
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	f.BoolP("verbose", "v", false, "")
+	if err := f.Parse([]string{"--unknown"}); err == nil {
+		t.Error("expected unknown flag error without passthrough")
+	}
+}
+
+func TestFlagHelp(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.StringP("output", "o", "out.txt", "write the result to `file`")
+	f.Bool("force", false, "overwrite existing files")
+	f.Int("level", 3, "compression level")
+	f.MarkDeprecated("level", "use --quality instead")
+
+	if usage, err := f.UsageOf("output"); err != nil || usage != "write the result to file" {
+		t.Errorf("expected unquoted usage, got %q, %v", usage, err)
+	}
+	expected := "-o, --output file\n    write the result to file\n    Default: \"out.txt\"\n"
+	if help, err := f.FlagHelp("output"); err != nil || help != expected {
+		t.Errorf("expected %q, got %q, %v", expected, help, err)
+	}
+	expected = "--force\n    overwrite existing files\n    Default: false\n"
+	if help, err := f.FlagHelp("force"); err != nil || help != expected {
+		t.Errorf("expected %q, got %q, %v", expected, help, err)
+	}
+	f.IntP("", "n", 1, "number of copies")
+	expected = "-n int\n    number of copies\n    Default: 1\n"
+	if help, err := f.FlagHelp("n"); err != nil || help != expected {
+		t.Errorf("expected %q, got %q, %v", expected, help, err)
+	}
+	help, err := f.FlagHelp("level")
+	if err != nil || !strings.Contains(help, "--level int\n") || !strings.Contains(help, "Deprecated: use --quality instead") {
+		t.Errorf("expected type and deprecation notice, got %q, %v", help, err)
+	}
+	if _, err := f.UsageOf("missing"); err == nil {
+		t.Error("expected error for unknown flag")
+	}
+	if _, err := f.FlagHelp("missing"); err == nil {
+		t.Error("expected error for unknown flag")
+	}
+}
+
+func TestVisitAllOrders(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.String("zeta", "", "")
+	f.Bool("alpha", false, "")
+	f.Int("mu", 0, "")
+	f.Bool("beta", false, "")
+
+	visit := func(iterate func(func(*Flag))) []string {
+		var names []string
+		iterate(func(flag *Flag) { names = append(names, flag.Name) })
+		return names
+	}
+	sorted := []string{"alpha", "beta", "mu", "zeta"}
+	if names := visit(f.VisitAllSorted); !reflect.DeepEqual(names, sorted) {
+		t.Errorf("expected sorted order %v, got %v", sorted, names)
+	}
+	if names := visit(f.VisitAll); !reflect.DeepEqual(names, sorted) {
+		t.Errorf("expected VisitAll to be sorted %v, got %v", sorted, names)
+	}
+	defined := []string{"zeta", "alpha", "mu", "beta"}
+	if names := visit(f.VisitAllInOrder); !reflect.DeepEqual(names, defined) {
+		t.Errorf("expected definition order %v, got %v", defined, names)
+	}
+
+	f.SetRedefinePolicy(RedefineReplace)
+	f.Int("alpha", 0, "")
+	defined = []string{"zeta", "mu", "beta", "alpha"}
+	if names := visit(f.VisitAllInOrder); !reflect.DeepEqual(names, defined) {
+		t.Errorf("expected a replaced flag to move to the end %v, got %v", defined, names)
+	}
+}
+
+func TestParseShortArg(t *testing.T) {
+	tests := []struct {
+		args  []string
+		a     bool
+		b     string
+		rest  []string
+		valid bool
+	}{
+		{[]string{"-ab=c"}, true, "c", []string{}, true},
+		{[]string{"-abc"}, true, "c", []string{}, true},
+		{[]string{"-a", "-b", "c"}, true, "c", []string{}, true},
+		{[]string{"-ab", "c", "d"}, true, "c", []string{"d"}, true},
+		{[]string{"-b=x"}, false, "x", []string{}, true},
+		{[]string{"-b=", "d"}, false, "", []string{"d"}, true},
+		{[]string{"-b=a=b"}, false, "a=b", []string{}, true},
+		{[]string{"-a=x"}, false, "", nil, false},
+		{[]string{"-a=true"}, true, "", []string{}, true},
+		{[]string{"-a=false", "d"}, false, "", []string{"d"}, true},
+		{[]string{"-=b"}, false, "", nil, false},
+	}
+	for _, test := range tests {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		a := f.BoolP("alpha", "a", false, "")
+		b := f.StringP("beta", "b", "", "")
+		err := f.Parse(test.args)
+		if !test.valid {
+			if err == nil {
+				t.Errorf("%q: expected an error", test.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: expected no error; got %v", test.args, err)
+			continue
+		}
+		if *a != test.a || *b != test.b {
+			t.Errorf("%q: expected a=%v b=%q, got a=%v b=%q", test.args, test.a, test.b, *a, *b)
+		}
+		if !reflect.DeepEqual(f.Args(), test.rest) {
+			t.Errorf("%q: expected args %v, got %v", test.args, test.rest, f.Args())
+		}
+	}
+}
+
+func TestUserDefinedHelpFlags(t *testing.T) {
+	newSet := func() (*FlagSet, *bool) {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		usageCalled := new(bool)
+		f.Usage = func() { *usageCalled = true }
+		return f, usageCalled
+	}
+
+	f, usageCalled := newSet()
+	help := f.BoolP("help", "h", false, "show help")
+	for _, args := range [][]string{{"--help"}, {"-h"}, {"--help=true"}} {
+		*help = false
+		if err := f.Parse(args); err != nil {
+			t.Errorf("%v: expected user-defined help flag to parse; got %v", args, err)
+		}
+		if !*help || *usageCalled {
+			t.Errorf("%v: expected help flag set without built-in usage, got help=%v usage=%v", args, *help, *usageCalled)
+		}
+	}
+
+	f, usageCalled = newSet()
+	host := f.StringP("host", "h", "", "host name")
+	if err := f.Parse([]string{"-h", "example.com"}); err != nil || *host != "example.com" || *usageCalled {
+		t.Errorf("expected -h to set --host, got host=%q usage=%v err=%v", *host, *usageCalled, err)
+	}
+	if err := f.Parse([]string{"--help"}); err != ErrHelp || !*usageCalled {
+		t.Errorf("expected built-in --help when only -h is taken, got %v", err)
+	}
+
+	f, usageCalled = newSet()
+	f.Bool("verbose", false, "")
+	if err := f.Parse([]string{"-h"}); err != ErrHelp || !*usageCalled {
+		t.Errorf("expected built-in -h to return ErrHelp, got %v", err)
+	}
+}
+
+func TestSetAnnotation(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.String("config", "", "config file")
+	f.Bool("verbose", false, "")
+
+	exts := []string{"yaml", "yml", "json"}
+	if err := f.SetAnnotation("config", "completion_extensions", exts); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	exts[0] = "changed"
+	if err := f.SetAnnotation("config", "group", []string{"input"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+
+	values, ok, err := f.GetAnnotation("config", "completion_extensions")
+	if err != nil || !ok || !reflect.DeepEqual(values, []string{"yaml", "yml", "json"}) {
+		t.Errorf("expected [yaml yml json], got %v, %v, %v", values, ok, err)
+	}
+	annotations := f.Lookup("config").Annotations
+	if len(annotations) != 2 || !reflect.DeepEqual(annotations["group"], []string{"input"}) {
+		t.Errorf("expected two annotations through Lookup, got %v", annotations)
+	}
+	if _, ok, err := f.GetAnnotation("verbose", "group"); ok || err != nil {
+		t.Errorf("expected no annotation on verbose, got %v, %v", ok, err)
+	}
+	if err := f.SetAnnotation("missing", "group", nil); err == nil {
+		t.Error("expected error annotating unknown flag")
+	}
+	if _, _, err := f.GetAnnotation("missing", "group"); err == nil {
+		t.Error("expected error for unknown flag")
+	}
+}
+
+func TestSortFlags(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Bool("zeta", false, "last letter")
+	f.Bool("alpha", false, "first letter")
+	f.Bool("mu", false, "middle letter")
+
+	var names []string
+	f.VisitAll(func(flag *Flag) { names = append(names, flag.Name) })
+	if expected := []string{"alpha", "mu", "zeta"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected sorted %v by default, got %v", expected, names)
+	}
+	sorted := "  --alpha   first letter\n  --mu      middle letter\n  --zeta    last letter\n"
+	if usages := f.FlagUsages(); usages != sorted {
+		t.Errorf("expected sorted usage %q, got %q", sorted, usages)
+	}
+
+	f.SortFlags = false
+	names = nil
+	f.VisitAll(func(flag *Flag) { names = append(names, flag.Name) })
+	if expected := []string{"zeta", "alpha", "mu"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected definition order %v, got %v", expected, names)
+	}
+	inOrder := "  --zeta    last letter\n  --alpha   first letter\n  --mu      middle letter\n"
+	if usages := f.FlagUsages(); usages != inOrder {
+		t.Errorf("expected usage in definition order %q, got %q", inOrder, usages)
+	}
+	var buf bytes.Buffer
+	f.SetOutput(&buf)
+	f.PrintDefaults()
+	if buf.String() != inOrder {
+		t.Errorf("expected PrintDefaults in definition order %q, got %q", inOrder, buf.String())
+	}
+
+	var g FlagSet
+	g.Init("test", ContinueOnError)
+	g.Bool("zeta", false, "last letter")
+	g.Bool("alpha", false, "first letter")
+	g.Bool("mu", false, "middle letter")
+	if usages := g.FlagUsages(); usages != sorted {
+		t.Errorf("expected Init to sort usage %q, got %q", sorted, usages)
+	}
+}
+
+func TestAddFlag(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.StringP("output", "o", "", "output file")
+
+	level := 0
+	plugin := &Flag{Name: "level", Shorthand: "l", Usage: "plugin level", Value: newIntValue(2, &level), DefValue: "2"}
+	if err := f.AddFlag(plugin); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if err := f.Parse([]string{"-l", "5"}); err != nil || level != 5 {
+		t.Errorf("expected added flag to parse, got %d, %v", level, err)
+	}
+
+	dup := &Flag{Name: "output", Value: newIntValue(0, new(int))}
+	if err := f.AddFlag(dup); err == nil || !strings.Contains(err.Error(), "redefined: output") {
+		t.Errorf("expected duplicate name error, got %v", err)
+	}
+	clash := &Flag{Name: "overwrite", Shorthand: "o", Value: newBoolValue(false, new(bool))}
+	if err := f.AddFlag(clash); err == nil || !strings.Contains(err.Error(), "shorthand reused") {
+		t.Errorf("expected duplicate shorthand error, got %v", err)
+	}
+	if f.Lookup("overwrite") != nil {
+		t.Error("expected clashing flag not to be added")
+	}
+	if err := f.AddFlag(&Flag{Name: "novalue"}); err == nil {
+		t.Error("expected error for a flag without a Value")
+	}
+}
+
+func TestReset(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	verbose := f.BoolP("verbose", "v", false, "")
+	name := f.String("name", "", "")
+	if err := f.Parse([]string{"-v", "--name=a", "x", "y"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if f.NFlag() != 2 || f.NArg() != 2 {
+		t.Fatalf("expected 2 flags and 2 args, got %d and %d", f.NFlag(), f.NArg())
+	}
+
+	f.Reset()
+	if f.Parsed() || f.NFlag() != 0 || f.NArg() != 0 || f.Changed("verbose") {
+		t.Fatalf("expected a fresh set after Reset, got parsed=%v flags=%d args=%d", f.Parsed(), f.NFlag(), f.NArg())
+	}
+	if !*verbose || *name != "a" {
+		t.Errorf("expected values to be kept across Reset, got verbose=%v name=%q", *verbose, *name)
+	}
+
+	if err := f.Parse([]string{"--name=b", "z"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if f.NFlag() != 1 || !reflect.DeepEqual(f.Args(), []string{"z"}) {
+		t.Errorf("expected 1 flag and args [z], got %d and %v", f.NFlag(), f.Args())
+	}
+	if f.Changed("verbose") || !f.Changed("name") || *name != "b" {
+		t.Errorf("expected only name to be set after re-parse")
+	}
+	if s := f.Lookup("verbose").Source; s != SourceDefault {
+		t.Errorf("expected verbose source to be reset to default, got %v", s)
+	}
+
+	f.MarkImmutable("name")
+	f.Reset()
+	if s := f.Lookup("name").Source; s != SourceDefault {
+		t.Errorf("expected name source to be reset to default, got %v", s)
+	}
+	if err := f.Parse([]string{"--name=c"}); err != nil {
+		t.Fatal("expected immutable flag to be settable after Reset; got ", err)
+	}
+	if *name != "c" || f.Lookup("name").Source != SourceCommandLine {
+		t.Errorf("expected name=c from the command line, got %q from %v", *name, f.Lookup("name").Source)
+	}
+	if err := f.Set("name", "d"); err == nil {
+		t.Error("expected immutable flag to be frozen again after re-parse")
+	}
+}
+
+func TestEmptyValue(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	output := f.String("output", "out.txt", "")
+	count := f.Int("count", 3, "")
+	tags := f.StringSlice("tag", []string{"a"}, "")
+	if err := f.Parse([]string{"--output=", "--tag="}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *output != "" || !f.Changed("output") {
+		t.Errorf("expected --output= to set an empty string, got %q changed=%v", *output, f.Changed("output"))
+	}
+	if len(*tags) != 0 {
+		t.Errorf("expected --tag= to clear the default, got %v", *tags)
+	}
+
+	err := f.Parse([]string{"--count="})
+	if err == nil || !strings.Contains(err.Error(), `invalid argument "" for --count=`) {
+		t.Errorf("expected --count= to report the int parse error, got %v", err)
+	}
+	if *count != 3 || f.Changed("count") {
+		t.Errorf("expected count to be unchanged, got %d", *count)
+	}
+}
+
+func TestArgsLenAtDash(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"a", "-v", "b"}, -1},
+		{[]string{"a", "--", "-v", "b"}, 1},
+		{[]string{"--", "a"}, 0},
+		{[]string{"-v", "a", "b", "--"}, 2},
+		{[]string{"a", "--", "--", "b"}, 1},
+	}
+	f := NewFlagSet("test", ContinueOnError)
+	f.BoolP("verbose", "v", false, "")
+	if n := f.ArgsLenAtDash(); n != -1 {
+		t.Errorf("expected -1 before parsing, got %d", n)
+	}
+	for _, test := range tests {
+		if err := f.Parse(test.args); err != nil {
+			t.Errorf("%v: expected no error; got %v", test.args, err)
+			continue
+		}
+		if n := f.ArgsLenAtDash(); n != test.expected {
+			t.Errorf("%v: expected %d, got %d", test.args, test.expected, n)
+		}
+	}
+}
+
+func TestVisitAllInOrderCommandLine(t *testing.T) {
+	ResetForTesting(nil)
+	var port, retries int
+	var host string
+	defined := []string{"port", "host", "retries", "debug"}
+	Var(newIntValue(8080, &port), "port", "")
+	VarP(newStringValue("localhost", &host), "host", "H", "")
+	IntVar(&retries, "retries", 3, "")
+	Bool("debug", false, "")
+
+	var names []string
+	VisitAllInOrder(func(flag *Flag) { names = append(names, flag.Name) })
+	if !reflect.DeepEqual(names, defined) {
+		t.Errorf("expected the order of the Var calls %v, got %v", defined, names)
+	}
+	names = nil
+	VisitAllSorted(func(flag *Flag) { names = append(names, flag.Name) })
+	if expected := []string{"debug", "host", "port", "retries"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected sorted order %v, got %v", expected, names)
+	}
+}
+
+func TestFlagUsagesAlignment(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.StringP("output", "o", "", "output `file`")
+	f.Bool("force", false, "overwrite existing files")
+	f.IntP("jobs", "j", 1, "parallel jobs")
+	f.Duration("timeout", 0, "give up after `d`")
+	want := "" +
+		"      --force         overwrite existing files\n" +
+		"  -j, --jobs int      parallel jobs (default 1)\n" +
+		"  -o, --output file   output file\n" +
+		"      --timeout d     give up after d (default 0s)\n"
+	got := f.FlagUsages()
+	if got != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, got)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\n"), "\n") {
+		if i := strings.Index(line, "--"); i != 6 {
+			t.Errorf("expected --name in column 6, got %d in %q", i, line)
+		}
+	}
+}
+
+func TestAddAlias(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	color := f.String("color", "auto", "when to use color")
+	f.Bool("verbose", false, "")
+	if err := f.AddAlias("color", "colour"); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if err := f.Parse([]string{"--colour=never"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	if *color != "never" || !f.Changed("color") {
+		t.Errorf("expected --colour to set color, got %q changed=%v", *color, f.Changed("color"))
+	}
+	if err := f.Parse([]string{"--color=always"}); err != nil || *color != "always" {
+		t.Errorf("expected --color to still work, got %q, %v", *color, err)
+	}
+	if f.Lookup("colour") != f.Lookup("color") {
+		t.Error("expected Lookup of the alias to return the canonical flag")
+	}
+	if usages := f.FlagUsages(); strings.Contains(usages, "colour") {
+		t.Errorf("expected alias to be hidden from usage, got %q", usages)
+	}
+
+	if err := f.AddAlias("color", "verbose"); err == nil {
+		t.Error("expected error aliasing an existing flag name")
+	}
+	if err := f.AddAlias("verbose", "colour"); err == nil {
+		t.Error("expected error reusing an alias")
+	}
+	if err := f.AddAlias("missing", "x"); err == nil {
+		t.Error("expected error aliasing an unknown flag")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	var out bytes.Buffer
+	f.SetOutput(&out)
+	name := f.String("name", "gopher", "")
+	count := f.Int("count", 1, "")
+	tags := f.StringSlice("tag", []string{"default"}, "")
+	f.String("user", "", "")
+	f.MarkRequired("user")
+	if err := f.Parse([]string{"--user=root", "--count=2", "a"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+
+	valid := []string{"--user=admin", "--name=x", "--count=9", "--tag=t", "b", "c"}
+	if err := f.Validate(valid); err != nil {
+		t.Errorf("expected %v to be valid; got %v", valid, err)
+	}
+	invalid := [][]string{
+		{"--user=admin", "--count=x"},
+		{"--user=admin", "--unknown"},
+	}
+	for _, args := range invalid {
+		if err := f.Validate(args); err == nil {
+			t.Errorf("expected %v to be invalid", args)
+		}
+	}
+	f.Reset()
+	if err := f.Validate([]string{"--name=x"}); err == nil || !strings.Contains(err.Error(), "[user]") {
+		t.Errorf("expected missing required flag error, got %v", err)
+	}
+	if err := f.Parse([]string{"--user=root", "--count=2", "a"}); err != nil {
+		t.Fatal("expected no error; got ", err)
+	}
+	f.Validate(valid)
+
+	if *name != "gopher" || *count != 2 || !reflect.DeepEqual(*tags, []string{"default"}) {
+		t.Errorf("expected values unchanged, got name=%q count=%d tags=%v", *name, *count, *tags)
+	}
+	if f.Changed("name") || !f.Changed("count") || f.NFlag() != 2 {
+		t.Errorf("expected set state unchanged, got %d flags set", f.NFlag())
+	}
+	if !reflect.DeepEqual(f.Args(), []string{"a"}) {
+		t.Errorf("expected args [a], got %v", f.Args())
+	}
+	if source, _ := f.Source("name"); source != SourceDefault {
+		t.Errorf("expected name's source to stay default, got %v", source)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected Validate to print nothing, got %q", out.String())
+	}
+}
+
+func TestValidateRestoresValues(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	tri := f.BoolTristate("tri", "")
+	since := f.Time("since", []string{"2006-01-02", time.RFC3339}, time.Time{}, "")
+	at := f.Time("at", []string{"2006-01-02", time.RFC3339}, time.Time{}, "")
+	size := f.Quantity("size", 2048, map[string]float64{"KiB": 1024, "MiB": 1 << 20}, "")
+	mode := f.StringEnum("mode", []string{"fast", "slow"}, "", "")
+	ratio := f.Float64Range("ratio", 0, 1, 2, "")
+	var list appendValue
+	f.Var(&list, "item", "")
+	if err := f.Parse([]string{"--at=2020-01-02T10:30:00Z", "--item=a", "--item=b"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	wantAt := *at
+
+	args := []string{"--tri", "--since=2021-05-06", "--at=2022-01-01", "--size=3MiB", "--mode=fast", "--ratio=0.5", "--item=c"}
+	if err := f.Validate(args); err != nil {
+		t.Fatalf("expected %v to be valid; got %v", args, err)
+	}
+	if *tri != nil {
+		t.Errorf("expected tri to stay unset; got %v", **tri)
+	}
+	if !since.IsZero() {
+		t.Errorf("expected since to stay zero; got %v", *since)
+	}
+	if !at.Equal(wantAt) {
+		t.Errorf("expected at to stay %v; got %v", wantAt, *at)
+	}
+	if *size != 2048 {
+		t.Errorf("expected size to stay 2048; got %v", *size)
+	}
+	if *mode != "" || *ratio != 2 {
+		t.Errorf("expected mode and ratio to keep their defaults; got %q, %v", *mode, *ratio)
+	}
+	if !reflect.DeepEqual(list, appendValue{"a", "b"}) {
+		t.Errorf("expected item to stay [a b]; got %v", list)
+	}
+	if f.Changed("tri") || f.Changed("size") || f.Changed("mode") || !f.Changed("at") || !f.Changed("item") {
+		t.Error("expected set state unchanged")
+	}
+	if err := f.Validate([]string{"--mode=medium"}); err == nil {
+		t.Error("expected a value outside the enum to be reported")
+	}
+}
+
+// appendValue is a user-defined Value that accumulates.
+type appendValue []string
+
+func (a *appendValue) Set(s string) error {
+	*a = append(*a, s)
+	return nil
+}
+
+func (a *appendValue) String() string { return strings.Join(*a, ",") }
+
+func TestAllowPrefixMatch(t *testing.T) {
+	newSet := func() (*FlagSet, *bool, *bool, *string) {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		f.AllowPrefixMatch = true
+		version := f.Bool("version", false, "")
+		verbose := f.Bool("verbose", false, "")
+		output := f.String("output", "", "")
+		return f, version, verbose, output
+	}
+
+	f, version, verbose, output := newSet()
+	if err := f.Parse([]string{"--vers", "--out=x", "--verbose"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !*version || !*verbose || *output != "x" {
+		t.Errorf("got version=%v verbose=%v output=%q", *version, *verbose, *output)
+	}
+	if !f.Changed("version") {
+		t.Error("expected a prefix to mark the full flag as changed")
+	}
+
+	f, _, _, _ = newSet()
+	err := f.Parse([]string{"--ver"})
+	if err == nil {
+		t.Fatal("expected an error for an ambiguous prefix")
+	}
+	if want := "ambiguous flag: --ver could be --verbose, --version"; err.Error() != want {
+		t.Errorf("expected %q; got %q", want, err)
+	}
+
+	f, _, _, _ = newSet()
+	if err := f.Parse([]string{"--nothing"}); err == nil || !strings.Contains(err.Error(), "unknown flag: --nothing") {
+		t.Errorf("expected an unknown flag error; got %v", err)
+	}
+
+	f, _, _, _ = newSet()
+	f.AllowPrefixMatch = false
+	if err := f.Parse([]string{"--out=x"}); err == nil {
+		t.Error("expected prefixes to be rejected by default")
+	}
+}
+
+func TestDefinedNames(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.BoolP("verbose", "v", false, "")
+	f.String("name", "", "")
+	f.IntP("count", "c", 0, "")
+	if names := f.DefinedNames(); !reflect.DeepEqual(names, []string{"count", "name", "verbose"}) {
+		t.Errorf("expected [count name verbose]; got %v", names)
+	}
+	if names := f.ShorthandNames(); !reflect.DeepEqual(names, []string{"c", "v"}) {
+		t.Errorf("expected [c v]; got %v", names)
+	}
+
+	names := f.DefinedNames()
+	names[0] = "changed"
+	shorthands := f.ShorthandNames()
+	shorthands[0] = "x"
+	if f.Lookup("count") == nil || f.DefinedNames()[0] != "count" || f.ShorthandNames()[0] != "c" {
+		t.Error("expected mutating the returned slices not to affect the flag set")
+	}
+
+	empty := NewFlagSet("empty", ContinueOnError)
+	if len(empty.DefinedNames()) != 0 || len(empty.ShorthandNames()) != 0 {
+		t.Error("expected no names for an empty flag set")
+	}
+}
+
+func TestResetFlag(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	count := f.Int("count", 3, "")
+	tags := f.StringSlice("tag", []string{"a", "b"}, "")
+	name := f.String("name", "gopher", "")
+	if err := f.Parse([]string{"--count=5", "--tag=c", "--name=x"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	for _, name := range []string{"count", "tag"} {
+		if err := f.ResetFlag(name); err != nil {
+			t.Fatalf("expected no error resetting %s; got %v", name, err)
+		}
+		if f.Changed(name) {
+			t.Errorf("expected %s not to be changed after ResetFlag", name)
+		}
+	}
+	if *count != 3 || !reflect.DeepEqual(*tags, []string{"a", "b"}) {
+		t.Errorf("expected defaults; got count=%d tag=%v", *count, *tags)
+	}
+	if src := f.Lookup("count").Source; src != SourceDefault {
+		t.Errorf("expected source %v; got %v", SourceDefault, src)
+	}
+	if *name != "x" || !f.Changed("name") {
+		t.Error("expected other flags to be left alone")
+	}
+	if err := f.ResetFlag("missing"); err == nil {
+		t.Error("expected an error for an unknown flag")
+	}
+
+	g := NewFlagSet("test", ContinueOnError)
+	tri := g.BoolTristate("tri", "")
+	since := g.Time("since", nil, time.Time{}, "")
+	deadline := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	until := g.Time("until", nil, deadline, "")
+	size := g.Quantity("size", 2048, map[string]float64{"KiB": 1024, "MiB": 1 << 20}, "")
+	args := []string{"--tri=false", "--since=2021-05-06T00:00:00Z", "--until=2022-01-01T00:00:00Z", "--size=3MiB"}
+	if err := g.Parse(args); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	for _, name := range []string{"tri", "since", "until", "size"} {
+		if err := g.ResetFlag(name); err != nil {
+			t.Errorf("expected no error resetting %s; got %v", name, err)
+		}
+		if g.Changed(name) {
+			t.Errorf("expected %s not to be changed after ResetFlag", name)
+		}
+	}
+	if *tri != nil {
+		t.Errorf("expected tri to be unset; got %v", **tri)
+	}
+	if !since.IsZero() || !until.Equal(deadline) {
+		t.Errorf("expected default times; got since=%v until=%v", *since, *until)
+	}
+	if *size != 2048 || g.Lookup("size").Value.String() != "2048" {
+		t.Errorf("expected size 2048; got %v", *size)
+	}
+
+	h := NewFlagSet("test", ContinueOnError)
+	mode := h.StringEnum("mode", []string{"fast", "slow"}, "", "")
+	ratio := h.Float64Range("ratio", 0, 1, 2, "")
+	mask := h.IPMask("mask", net.IPv4Mask(255, 255, 255, 0), "")
+	if err := h.Parse([]string{"--mode=fast", "--ratio=0.5", "--mask=255.0.0.0"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	for _, name := range []string{"mode", "ratio", "mask"} {
+		if err := h.ResetFlag(name); err != nil {
+			t.Errorf("expected no error resetting %s; got %v", name, err)
+		}
+	}
+	if *mode != "" || *ratio != 2 || !reflect.DeepEqual(*mask, net.IPv4Mask(255, 255, 255, 0)) {
+		t.Errorf("expected defaults; got mode=%q ratio=%v mask=%v", *mode, *ratio, *mask)
+	}
+}
+
+func TestCommandLineUsageOutput(t *testing.T) {
+	defer func(usage func()) { Usage = usage }(Usage)
+	ResetForTesting(commandLineUsage)
+	var buf bytes.Buffer
+	CommandLine.SetOutput(&buf)
+	Bool("verbose", false, "print more")
+	if err := CommandLine.Parse([]string{"--undefined"}); err == nil {
+		t.Fatal("expected an error for an undefined flag")
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Usage of "+os.Args[0]) || !strings.Contains(out, "--verbose") {
+		t.Errorf("expected the usage message in the configured output; got %q", out)
+	}
+}
+
+func TestInvalidBoolValue(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	debug := f.Bool("debug", false, "")
+	err := f.Parse([]string{"--debug=notabool", "arg"})
+	if err == nil {
+		t.Fatal("expected an error for an invalid bool value")
+	}
+	if !strings.Contains(err.Error(), `invalid argument "notabool" for --debug=notabool`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if *debug || f.Changed("debug") {
+		t.Error("expected debug to be left unset")
+	}
+
+	// An implied value that the flag rejects is reported too.
+	f = NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	f.Int("level", 0, "")
+	f.Lookup("level").NoOptDefVal = "high"
+	if err := f.Parse([]string{"--level"}); err == nil {
+		t.Error("expected an error for an invalid implied value")
+	}
+}
+
+func TestDisallowDuplicates(t *testing.T) {
+	newSet := func() (*FlagSet, *string) {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		output := f.StringP("output", "o", "", "")
+		f.StringSlice("tag", nil, "")
+		f.StringMap("label", nil, "")
+		f.CountP("verbose", "v", "")
+		return f, output
+	}
+	args := []string{"--output=a", "-o", "b"}
+
+	f, output := newSet()
+	if err := f.Parse(args); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if *output != "b" {
+		t.Errorf("expected the last value to win by default; got %q", *output)
+	}
+
+	f, output = newSet()
+	f.DisallowDuplicates = true
+	err := f.Parse(args)
+	if err == nil || err.Error() != "flag --output given more than once" {
+		t.Fatalf("expected a duplicate flag error; got %v", err)
+	}
+	if *output != "a" {
+		t.Errorf("expected the first value to be kept; got %q", *output)
+	}
+
+	f, _ = newSet()
+	f.DisallowDuplicates = true
+	if err := f.Parse([]string{"--tag=a", "--tag=b", "--label=a=1", "--label=b=2", "-vv", "-v"}); err != nil {
+		t.Errorf("expected accumulating flags to be exempt; got %v", err)
+	}
+	if err := f.Parse([]string{"--output=a"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if err := f.Parse([]string{"--output=b"}); err != nil {
+		t.Errorf("expected a flag given in an earlier Parse not to count; got %v", err)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		args  []string
+		kind  ParseErrorKind
+		flag  string
+		token string
+	}{
+		{[]string{"--missing"}, UnknownFlag, "missing", "--missing"},
+		{[]string{"-x"}, UnknownFlag, "x", "-x"},
+		{[]string{"--count"}, MissingValue, "count", "--count"},
+		{[]string{"-vc"}, MissingValue, "count", "-vc"},
+		{[]string{"--count=x"}, InvalidValue, "count", "--count=x"},
+		{[]string{"-c", "x"}, InvalidValue, "count", "-c"},
+		{[]string{"---count"}, BadSyntax, "", "---count"},
+		{[]string{"-v=x"}, InvalidValue, "verbose", "-v=x"},
+		{[]string{"-=v"}, BadSyntax, "", "-=v"},
+	}
+	for _, test := range tests {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		f.IntP("count", "c", 0, "")
+		f.BoolP("verbose", "v", false, "")
+		err := f.Parse(test.args)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%v: expected a ParseError; got %#v", test.args, err)
+			continue
+		}
+		if perr.Kind != test.kind || perr.Flag != test.flag || perr.Token != test.token {
+			t.Errorf("%v: expected %v for %q in %q; got %v for %q in %q", test.args,
+				test.kind, test.flag, test.token, perr.Kind, perr.Flag, perr.Token)
+		}
+		if (perr.Kind == InvalidValue) != (perr.Err != nil) {
+			t.Errorf("%v: expected Err only for an invalid value; got %v", test.args, perr.Err)
+		}
+	}
+
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	f.Int("count", 0, "")
+	err := f.Parse([]string{"--count=x"})
+	if want := `invalid argument "x" for --count=x: strconv.ParseInt: parsing "x": invalid syntax`; err == nil || err.Error() != want {
+		t.Errorf("expected %q; got %v", want, err)
+	}
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Errorf("expected the Value's error to be unwrapped; got %#v", err)
+	}
+}
+
+func TestExpandArgsFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pflag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	inner := write("inner", "--tag=c\n")
+	outer := write("outer", "--count=5 --name gopher\n--tag=a -v\n@"+inner+"\n")
+
+	newSet := func() (*FlagSet, *int, *string, *[]string, *bool) {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		f.SetExpandArgsFromFile(true)
+		count := f.Int("count", 0, "")
+		name := f.String("name", "", "")
+		tags := f.StringSlice("tag", nil, "")
+		verbose := f.BoolP("verbose", "v", false, "")
+		return f, count, name, tags, verbose
+	}
+
+	f, count, name, tags, verbose := newSet()
+	if err := f.Parse([]string{"@" + outer, "--tag=d", "arg", "--", "@" + outer}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if *count != 5 || *name != "gopher" || !*verbose || !reflect.DeepEqual(*tags, []string{"a", "c", "d"}) {
+		t.Errorf("got count=%d name=%q verbose=%v tag=%v", *count, *name, *verbose, *tags)
+	}
+	if want := []string{"arg", "@" + outer}; !reflect.DeepEqual(f.Args(), want) {
+		t.Errorf("expected args %v; got %v", want, f.Args())
+	}
+
+	f, _, _, _, _ = newSet()
+	err = f.Parse([]string{"@" + filepath.Join(dir, "missing")})
+	if err == nil || !strings.Contains(err.Error(), "reading arguments from @") {
+		t.Errorf("expected an error for a missing file; got %v", err)
+	}
+
+	f, _, _, _, _ = newSet()
+	f.SetFileExpansionLimits(1, 0)
+	if err := f.Parse([]string{"@" + outer}); err == nil || !strings.Contains(err.Error(), "nested more than 1 files deep") {
+		t.Errorf("expected an error for a nested file beyond the limit; got %v", err)
+	}
+
+	loop := write("loop", "@"+filepath.Join(dir, "loop"))
+	f, _, _, _, _ = newSet()
+	if err := f.Parse([]string{"@" + loop}); err == nil {
+		t.Error("expected an error for a file that names itself")
+	}
+
+	f, _, _, _, _ = newSet()
+	f.SetFileExpansionLimits(0, 4)
+	if err := f.Parse([]string{"@" + inner}); err == nil || !strings.Contains(err.Error(), "larger than 4 bytes") {
+		t.Errorf("expected an error for a file over the size limit; got %v", err)
+	}
+
+	f, _, name, _, _ = newSet()
+	f.SetExpandArgsFromFile(false)
+	if err := f.Parse([]string{"--name", "@" + inner}); err != nil || *name != "@"+inner {
+		t.Errorf("expected @file to be literal when expansion is off; got %q, %v", *name, err)
+	}
+}
+
+func TestFileExpansionLimits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pflag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// chain[i] names chain[i+1], so @chain[0] nests len(chain) files deep.
+	chain := make([]string, DefaultFileExpansionDepth+1)
+	for i := range chain {
+		chain[i] = filepath.Join(dir, fmt.Sprintf("nested%d", i))
+	}
+	for i, path := range chain {
+		content := "--count=1\n"
+		if i+1 < len(chain) {
+			content = "@" + chain[i+1] + "\n"
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	big := filepath.Join(dir, "big")
+	if err := ioutil.WriteFile(big, []byte(strings.Repeat("--count=1 ", 100)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parse := func(maxDepth int, maxBytes int64, args ...string) error {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		f.SetExpandArgsFromFile(true)
+		f.Int("count", 0, "")
+		f.SetFileExpansionLimits(maxDepth, maxBytes)
+		return f.Parse(args)
+	}
+	tooDeep := fmt.Sprintf("nested more than %d files deep", DefaultFileExpansionDepth)
+	if err := parse(0, 0, "@"+chain[0]); err == nil || !strings.Contains(err.Error(), tooDeep) {
+		t.Errorf("expected %q by default; got %v", tooDeep, err)
+	}
+	if err := parse(0, 0, "@"+chain[1]); err != nil {
+		t.Errorf("expected %d nested files to be allowed by default; got %v", DefaultFileExpansionDepth, err)
+	}
+	if err := parse(3, 0, "@"+chain[len(chain)-4]); err == nil || !strings.Contains(err.Error(), "nested more than 3 files deep") {
+		t.Errorf("expected the configured depth to be enforced; got %v", err)
+	}
+	if err := parse(3, 0, "@"+chain[len(chain)-3]); err != nil {
+		t.Errorf("expected 3 nested files to be allowed; got %v", err)
+	}
+	if err := parse(0, 999, "@"+big); err == nil || !strings.Contains(err.Error(), "larger than 999 bytes") {
+		t.Errorf("expected the configured size to be enforced; got %v", err)
+	}
+	if err := parse(-1, -1, "@"+big); err != nil {
+		t.Errorf("expected negative limits to restore the defaults; got %v", err)
+	}
+}
+
+func TestShorthandOnlyFlag(t *testing.T) {
+	newSet := func() (*FlagSet, *bool, *string) {
+		f := NewFlagSet("test", ContinueOnError)
+		f.SetOutput(ioutil.Discard)
+		x := f.BoolP("", "x", false, "extract files")
+		file := f.StringP("", "f", "", "archive `file`")
+		f.BoolP("verbose", "v", false, "verbose output")
+		return f, x, file
+	}
+
+	f, x, file := newSet()
+	if err := f.Parse([]string{"-x", "-f", "a.tar"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !*x || *file != "a.tar" {
+		t.Errorf("got x=%v f=%q", *x, *file)
+	}
+	if !f.Changed("x") || f.Lookup("f") == nil {
+		t.Error("expected shorthand-only flags to be found by their shorthand")
+	}
+	if names := f.DefinedNames(); !reflect.DeepEqual(names, []string{"verbose"}) {
+		t.Errorf("expected shorthand-only flags to be left out of DefinedNames, got %v", names)
+	}
+	if names := f.ShorthandNames(); !reflect.DeepEqual(names, []string{"f", "v", "x"}) {
+		t.Errorf("expected [f v x] from ShorthandNames, got %v", names)
+	}
+	if !f.ShorthandOnly("x") || f.ShorthandOnly("v") || f.ShorthandOnly("verbose") || f.ShorthandOnly("missing") {
+		t.Error("expected ShorthandOnly to report only -f and -x")
+	}
+
+	f, x, file = newSet()
+	if err := f.Parse([]string{"-xvfb.tar"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !*x || *file != "b.tar" {
+		t.Errorf("clustered: got x=%v f=%q", *x, *file)
+	}
+
+	f, _, _ = newSet()
+	if err := f.Parse([]string{"--x"}); err == nil || !strings.Contains(err.Error(), "unknown flag: --x") {
+		t.Errorf("expected --x to be unknown; got %v", err)
+	}
+
+	want := "" +
+		"  -f file         archive file\n" +
+		"  -v, --verbose   verbose output\n" +
+		"  -x              extract files\n"
+	if got := f.FlagUsages(); got != want {
+		t.Errorf("expected usage:\n%s\ngot:\n%s", want, got)
+	}
+
+	if err := f.VarPE(new(boolValue), "", "", ""); err == nil {
+		t.Error("expected an error for a flag with neither name nor shorthand")
+	}
+	if err := f.VarPE(new(boolValue), "x", "", ""); err == nil {
+		t.Error("expected an error for a long name taken by a shorthand-only flag")
+	}
+}
+
+func TestMarkImmutable(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	root := f.String("root-dir", "/", "")
+	count := f.Int("count", 0, "")
+	tags := f.StringSlice("tag", nil, "")
+	for _, name := range []string{"root-dir", "count", "tag"} {
+		if err := f.MarkImmutable(name); err != nil {
+			t.Fatal("expected no error; got", err)
+		}
+	}
+	if err := f.MarkImmutable("missing"); err == nil {
+		t.Error("expected an error for an unknown flag")
+	}
+
+	if err := f.Parse([]string{"--root-dir=/srv", "--tag=a"}); err != nil {
+		t.Fatal("expected the first set to succeed; got", err)
+	}
+	if *root != "/srv" {
+		t.Errorf("expected /srv; got %q", *root)
+	}
+	if err := f.Set("root-dir", "/etc"); err == nil {
+		t.Error("expected a second Set to be rejected")
+	}
+	if err := f.Parse([]string{"--root-dir=/etc"}); err == nil {
+		t.Error("expected a second set on the command line to be rejected")
+	}
+	if err := f.ApplyJSON(strings.NewReader(`{"tag": ["b"]}`)); err != nil {
+		t.Error("expected ApplyJSON to skip a flag that has been set; got", err)
+	}
+	if err := f.ResetFlag("root-dir"); err == nil {
+		t.Error("expected ResetFlag to be rejected")
+	}
+	if err := f.Restore(map[string]string{"root-dir": "/c"}); err == nil {
+		t.Error("expected Restore to be rejected")
+	}
+	if err := f.Restore(map[string]string{"root-dir": "/srv"}); err != nil {
+		t.Error("expected Restore to allow an unchanged value; got", err)
+	}
+	if err := f.Validate([]string{"--count=4"}); err != nil {
+		t.Error("expected Validate to leave immutable flags alone; got", err)
+	}
+	if *root != "/srv" || !reflect.DeepEqual(*tags, []string{"a"}) {
+		t.Errorf("expected values to be unchanged; got root-dir=%q tag=%v", *root, *tags)
+	}
+
+	if err := f.SetInt("count", 1); err != nil {
+		t.Fatal("expected the first set to succeed; got", err)
+	}
+	if err := f.SetInt("count", 2); err == nil || *count != 1 {
+		t.Errorf("expected a second SetInt to be rejected; got %v, count=%d", err, *count)
+	}
+
+	g := NewFlagSet("test", ContinueOnError)
+	g.Int("level", 0, "")
+	g.Set("level", "1")
+	g.MarkImmutable("level")
+	if err := g.Set("level", "2"); err == nil {
+		t.Error("expected a flag already set when marked to be immutable")
+	}
+
+	h := NewFlagSet("test", ContinueOnError)
+	h.SetOutput(ioutil.Discard)
+	hosts := h.StringSlice("host", nil, "")
+	h.MarkImmutable("host")
+	if err := h.ApplyJSON(strings.NewReader(`{"host": ["a", "b"]}`)); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if err := h.Parse([]string{"--host=c"}); err != nil {
+		t.Error("expected the command line to override a config value; got", err)
+	}
+	if !reflect.DeepEqual(*hosts, []string{"c"}) {
+		t.Errorf("expected [c]; got %v", *hosts)
+	}
+	if err := h.Parse([]string{"--host=d"}); err == nil {
+		t.Error("expected a second set after the config value to be rejected")
+	}
+
+	i := NewFlagSet("test", ContinueOnError)
+	i.SetOutput(ioutil.Discard)
+	root = i.String("root", "/", "")
+	i.MarkImmutable("root")
+	if err := i.ApplyJSON(strings.NewReader(`{"root": "/cfg"}`)); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if err := i.Parse([]string{"--root=/cli"}); err != nil || *root != "/cli" {
+		t.Errorf("expected the command line to override an immutable flag's config value; got %q, %v", *root, err)
+	}
+}
+
+// loggingValue records each value set through it before passing it on.
+type loggingValue struct {
+	Value
+	log []string
+}
+
+func (l *loggingValue) Set(s string) error {
+	l.log = append(l.log, s)
+	return l.Value.Set(s)
+}
+
+func TestReplaceValue(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	count := f.IntP("count", "c", 3, "")
+	wrapped := &loggingValue{Value: f.Lookup("count").Value}
+	if err := f.ReplaceValue("count", wrapped); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if flag := f.Lookup("count"); flag.Value != wrapped || flag.DefValue != "3" {
+		t.Errorf("expected the new Value and its default; got %v, %q", flag.Value, flag.DefValue)
+	}
+	if err := f.Parse([]string{"--count=5", "-c", "7"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !reflect.DeepEqual(wrapped.log, []string{"5", "7"}) {
+		t.Errorf("expected the new Value's Set to be called for both forms; got %v", wrapped.log)
+	}
+	if *count != 7 {
+		t.Errorf("expected the wrapped value to be updated; got %d", *count)
+	}
+
+	var s stringValue = "other"
+	if err := f.ReplaceValue("count", &s); err != nil || f.Lookup("count").DefValue != "other" {
+		t.Errorf("expected DefValue from the new Value; got %q, %v", f.Lookup("count").DefValue, err)
+	}
+	if err := f.ReplaceValue("missing", &s); err == nil {
+		t.Error("expected an error for an unknown flag")
+	}
+	if err := f.ReplaceValue("count", nil); err == nil {
+		t.Error("expected an error for a nil Value")
+	}
+}
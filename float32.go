@@ -13,14 +13,33 @@ func newFloat32Value(val float32, p *float32) *float32Value {
 	return (*float32Value)(p)
 }
 
+// Set parses s as a float32. Values outside the float32 range are rejected
+// and leave the flag unchanged.
 func (f *float32Value) Set(s string) error {
 	v, err := strconv.ParseFloat(s, 32)
+	if err != nil {
+		return err
+	}
 	*f = float32Value(v)
-	return err
+	return nil
 }
 
 func (f *float32Value) String() string { return fmt.Sprintf("%v", *f) }
 
+func (f *float32Value) Type() string { return "float32" }
+
+func (f *float32Value) clone() Value {
+	v := *f
+	return &v
+}
+
+func (f *float32Value) save() func() {
+	v := *f
+	return func() { *f = v }
+}
+
+func (f *float32Value) Get() interface{} { return float32(*f) }
+
 // Float32Var defines a float32 flag with specified name, default value, and usage string.
 // The argument p points to a float32 variable in which to store the value of the flag.
 func (f *FlagSet) Float32Var(p *float32, name string, value float32, usage string) {
@@ -68,3 +87,35 @@ func Float32(name string, value float32, usage string) *float32 {
 func Float32P(name, shorthand string, value float32, usage string) *float32 {
 	return CommandLine.Float32P(name, shorthand, value, usage)
 }
+
+// GetFloat32 returns the float32 value of the named flag.
+func (f *FlagSet) GetFloat32(name string) (float32, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return 0, err
+	}
+	tv, ok := v.(*float32Value)
+	if !ok {
+		return 0, fmt.Errorf("flag -%v is not a float32 flag", name)
+	}
+	return float32(*tv), nil
+}
+
+// SetFloat32 sets the named float32 flag to value and marks it as set, as Set
+// does, without formatting and parsing the value.
+func (f *FlagSet) SetFloat32(name string, value float32) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	tv, ok := flag.Value.(*float32Value)
+	if !ok {
+		return fmt.Errorf("flag -%v is not a float32 flag", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	*tv = float32Value(value)
+	f.markSet(flag, SourceAPI)
+	return nil
+}
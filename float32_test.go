@@ -0,0 +1,50 @@
+package pflag
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestFloat32(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	ratio := f.Float32P("ratio", "r", 0.5, "ratio")
+	tests := []struct {
+		arg      string
+		expected float32
+	}{
+		{"--ratio=1.25", 1.25},
+		{"--ratio=-3.5", -3.5},
+		{"-r1e10", 1e10},
+	}
+	for _, test := range tests {
+		if err := f.Parse([]string{test.arg}); err != nil {
+			t.Errorf("%s: expected no error; got %v", test.arg, err)
+			continue
+		}
+		if *ratio != test.expected {
+			t.Errorf("%s: expected %v but got %v", test.arg, test.expected, *ratio)
+		}
+		if v, err := f.GetFloat32("ratio"); err != nil || v != test.expected {
+			t.Errorf("%s: expected GetFloat32 to return %v but got %v, %v", test.arg, test.expected, v, err)
+		}
+	}
+}
+
+func TestFloat32OutOfRange(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	ratio := f.Float32("ratio", 0.5, "ratio")
+	for _, arg := range []string{"--ratio=1e39", "--ratio=-1e39", "--ratio=x"} {
+		if err := f.Parse([]string{arg}); err == nil {
+			t.Errorf("%s: expected an error", arg)
+		}
+		if *ratio != 0.5 {
+			t.Errorf("%s: expected the value to be unchanged but got %v", arg, *ratio)
+		}
+	}
+	f.Float64("big", 0, "")
+	if _, err := f.GetFloat32("big"); err == nil {
+		t.Error("expected an error getting a float64 flag as float32")
+	}
+}
@@ -15,12 +15,29 @@ func newFloat64Value(val float64, p *float64) *float64Value {
 
 func (f *float64Value) Set(s string) error {
 	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
 	*f = float64Value(v)
-	return err
+	return nil
 }
 
 func (f *float64Value) String() string { return fmt.Sprintf("%v", *f) }
 
+func (f *float64Value) Type() string { return "float64" }
+
+func (f *float64Value) clone() Value {
+	v := *f
+	return &v
+}
+
+func (f *float64Value) save() func() {
+	v := *f
+	return func() { *f = v }
+}
+
+func (f *float64Value) Get() interface{} { return float64(*f) }
+
 // Float64Var defines a float64 flag with specified name, default value, and usage string.
 // The argument p points to a float64 variable in which to store the value of the flag.
 func (f *FlagSet) Float64Var(p *float64, name string, value float64, usage string) {
@@ -68,3 +85,35 @@ func Float64(name string, value float64, usage string) *float64 {
 func Float64P(name, shorthand string, value float64, usage string) *float64 {
 	return CommandLine.Float64P(name, shorthand, value, usage)
 }
+
+// GetFloat64 returns the float64 value of the named flag.
+func (f *FlagSet) GetFloat64(name string) (float64, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return 0, err
+	}
+	tv, ok := v.(*float64Value)
+	if !ok {
+		return 0, fmt.Errorf("flag -%v is not a float64 flag", name)
+	}
+	return float64(*tv), nil
+}
+
+// SetFloat64 sets the named float64 flag to value and marks it as set, as Set
+// does, without formatting and parsing the value.
+func (f *FlagSet) SetFloat64(name string, value float64) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	tv, ok := flag.Value.(*float64Value)
+	if !ok {
+		return fmt.Errorf("flag -%v is not a float64 flag", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	*tv = float64Value(value)
+	f.markSet(flag, SourceAPI)
+	return nil
+}
@@ -0,0 +1,100 @@
+package pflag
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// -- float64 Value restricted to a range
+type float64RangeValue struct {
+	value    *float64
+	min, max float64
+}
+
+func newFloat64RangeValue(min, max float64, val float64, p *float64) *float64RangeValue {
+	*p = val
+	return &float64RangeValue{value: p, min: min, max: max}
+}
+
+// Set accepts s only if it parses to a number within [min, max]. NaN is
+// never in range.
+func (r *float64RangeValue) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(v) || v < r.min || v > r.max {
+		return fmt.Errorf("must be in the range [%v, %v]", r.min, r.max)
+	}
+	*r.value = v
+	return nil
+}
+
+func (r *float64RangeValue) String() string { return fmt.Sprintf("%v", *r.value) }
+
+func (r *float64RangeValue) Type() string { return "float64" }
+
+func (r *float64RangeValue) clone() Value {
+	v := *r.value
+	return &float64RangeValue{value: &v, min: r.min, max: r.max}
+}
+
+func (r *float64RangeValue) save() func() {
+	v := *r.value
+	return func() { *r.value = v }
+}
+
+func (r *float64RangeValue) Get() interface{} { return *r.value }
+
+// Float64RangeVar defines a float64 flag with specified name, inclusive bounds, default value,
+// and usage string. Setting the flag to a value outside [min, max] is an error.
+// The argument p points to a float64 variable in which to store the value of the flag.
+func (f *FlagSet) Float64RangeVar(p *float64, name string, min, max float64, value float64, usage string) {
+	f.VarP(newFloat64RangeValue(min, max, value, p), name, "", usage)
+}
+
+// Like Float64RangeVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) Float64RangeVarP(p *float64, name, shorthand string, min, max float64, value float64, usage string) {
+	f.VarP(newFloat64RangeValue(min, max, value, p), name, shorthand, usage)
+}
+
+// Float64RangeVar defines a float64 flag with specified name, inclusive bounds, default value,
+// and usage string. Setting the flag to a value outside [min, max] is an error.
+// The argument p points to a float64 variable in which to store the value of the flag.
+func Float64RangeVar(p *float64, name string, min, max float64, value float64, usage string) {
+	CommandLine.VarP(newFloat64RangeValue(min, max, value, p), name, "", usage)
+}
+
+// Like Float64RangeVar, but accepts a shorthand letter that can be used after a single dash.
+func Float64RangeVarP(p *float64, name, shorthand string, min, max float64, value float64, usage string) {
+	CommandLine.VarP(newFloat64RangeValue(min, max, value, p), name, shorthand, usage)
+}
+
+// Float64Range defines a float64 flag with specified name, inclusive bounds, default value,
+// and usage string. The return value is the address of a float64 variable that stores
+// the value of the flag.
+func (f *FlagSet) Float64Range(name string, min, max float64, value float64, usage string) *float64 {
+	p := new(float64)
+	f.Float64RangeVarP(p, name, "", min, max, value, usage)
+	return p
+}
+
+// Like Float64Range, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) Float64RangeP(name, shorthand string, min, max float64, value float64, usage string) *float64 {
+	p := new(float64)
+	f.Float64RangeVarP(p, name, shorthand, min, max, value, usage)
+	return p
+}
+
+// Float64Range defines a float64 flag with specified name, inclusive bounds, default value,
+// and usage string. The return value is the address of a float64 variable that stores
+// the value of the flag.
+func Float64Range(name string, min, max float64, value float64, usage string) *float64 {
+	return CommandLine.Float64RangeP(name, "", min, max, value, usage)
+}
+
+// Like Float64Range, but accepts a shorthand letter that can be used after a single dash.
+func Float64RangeP(name, shorthand string, min, max float64, value float64, usage string) *float64 {
+	return CommandLine.Float64RangeP(name, shorthand, min, max, value, usage)
+}
@@ -0,0 +1,44 @@
+package pflag
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestFloat64Range(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	ratio := f.Float64RangeP("ratio", "r", 0, 1, 0.5, "sampling ratio")
+	for _, arg := range []string{"0", "1", "0.25"} {
+		if err := f.Parse([]string{"--ratio=" + arg}); err != nil {
+			t.Fatalf("expected no error for %s; got %v", arg, err)
+		}
+	}
+	if *ratio != 0.25 {
+		t.Fatalf("expected 0.25 but got %v", *ratio)
+	}
+
+	for _, arg := range []string{"-0.1", "1.5", "NaN", "x"} {
+		err := f.Parse([]string{"-r", arg})
+		if err == nil {
+			t.Errorf("expected an error for %s", arg)
+			continue
+		}
+		if arg != "x" && !strings.Contains(err.Error(), "must be in the range [0, 1]") {
+			t.Errorf("expected an error stating the range for %s; got %v", arg, err)
+		}
+		if *ratio != 0.25 {
+			t.Fatalf("expected the value to be unchanged by %s but got %v", arg, *ratio)
+		}
+	}
+}
+
+func TestFloat64RangeUsage(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Float64Range("ratio", 0, 1, 0.5, "sampling ratio")
+	want := "  --ratio float   sampling ratio (range [0, 1]) (default 0.5)\n"
+	if got := f.FlagUsages(); got != want {
+		t.Fatalf("expected %q but got %q", want, got)
+	}
+}
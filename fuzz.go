@@ -0,0 +1,29 @@
+//go:build gofuzz
+// +build gofuzz
+
+package pflag
+
+import (
+	"io/ioutil"
+	"strings"
+)
+
+// Fuzz is the entry point for go-fuzz. It splits data into arguments on
+// NUL bytes and parses them with a representative flag set, which must
+// never panic or hang regardless of input.
+func Fuzz(data []byte) int {
+	f := NewFlagSet("fuzz", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	f.Usage = func() {}
+	f.BoolP("bool", "b", false, "bool value")
+	f.BoolP("verbose", "v", false, "bool value")
+	f.IntP("int", "i", 0, "int value")
+	f.StringP("string", "s", "", "string value")
+	f.DurationP("duration", "d", 0, "duration value")
+	f.Bitmask("caps", map[string]int{"read": 1, "write": 2}, "bitmask value")
+	args := strings.Split(string(data), "\x00")
+	if err := f.Parse(args); err != nil {
+		return 0
+	}
+	return 1
+}
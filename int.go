@@ -15,12 +15,29 @@ func newIntValue(val int, p *int) *intValue {
 
 func (i *intValue) Set(s string) error {
 	v, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return err
+	}
 	*i = intValue(v)
-	return err
+	return nil
 }
 
 func (i *intValue) String() string { return fmt.Sprintf("%v", *i) }
 
+func (i *intValue) Type() string { return "int" }
+
+func (i *intValue) clone() Value {
+	v := *i
+	return &v
+}
+
+func (i *intValue) save() func() {
+	v := *i
+	return func() { *i = v }
+}
+
+func (i *intValue) Get() interface{} { return int(*i) }
+
 // IntVar defines an int flag with specified name, default value, and usage string.
 // The argument p points to an int variable in which to store the value of the flag.
 func (f *FlagSet) IntVar(p *int, name string, value int, usage string) {
@@ -68,3 +85,35 @@ func Int(name string, value int, usage string) *int {
 func IntP(name, shorthand string, value int, usage string) *int {
 	return CommandLine.IntP(name, shorthand, value, usage)
 }
+
+// GetInt returns the int value of the named flag.
+func (f *FlagSet) GetInt(name string) (int, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return 0, err
+	}
+	tv, ok := v.(*intValue)
+	if !ok {
+		return 0, fmt.Errorf("flag -%v is not an int flag", name)
+	}
+	return int(*tv), nil
+}
+
+// SetInt sets the named int flag to value and marks it as set, as Set
+// does, without formatting and parsing the value.
+func (f *FlagSet) SetInt(name string, value int) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	tv, ok := flag.Value.(*intValue)
+	if !ok {
+		return fmt.Errorf("flag -%v is not an int flag", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	*tv = intValue(value)
+	f.markSet(flag, SourceAPI)
+	return nil
+}
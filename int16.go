@@ -0,0 +1,119 @@
+package pflag
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// -- int16 Value
+type int16Value int16
+
+func newInt16Value(val int16, p *int16) *int16Value {
+	*p = val
+	return (*int16Value)(p)
+}
+
+func (i *int16Value) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, 16)
+	if err != nil {
+		return err
+	}
+	*i = int16Value(v)
+	return nil
+}
+
+func (i *int16Value) String() string { return fmt.Sprintf("%v", *i) }
+
+func (i *int16Value) Type() string { return "int16" }
+
+func (i *int16Value) clone() Value {
+	v := *i
+	return &v
+}
+
+func (i *int16Value) save() func() {
+	v := *i
+	return func() { *i = v }
+}
+
+func (i *int16Value) Get() interface{} { return int16(*i) }
+
+// Int16Var defines an int16 flag with specified name, default value, and usage string.
+// The argument p points to an int16 variable in which to store the value of the flag.
+func (f *FlagSet) Int16Var(p *int16, name string, value int16, usage string) {
+	f.VarP(newInt16Value(value, p), name, "", usage)
+}
+
+// Like Int16Var, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) Int16VarP(p *int16, name, shorthand string, value int16, usage string) {
+	f.VarP(newInt16Value(value, p), name, shorthand, usage)
+}
+
+// Int16Var defines an int16 flag with specified name, default value, and usage string.
+// The argument p points to an int16 variable in which to store the value of the flag.
+func Int16Var(p *int16, name string, value int16, usage string) {
+	CommandLine.VarP(newInt16Value(value, p), name, "", usage)
+}
+
+// Like Int16Var, but accepts a shorthand letter that can be used after a single dash.
+func Int16VarP(p *int16, name, shorthand string, value int16, usage string) {
+	CommandLine.VarP(newInt16Value(value, p), name, shorthand, usage)
+}
+
+// Int16 defines an int16 flag with specified name, default value, and usage string.
+// The return value is the address of an int16 variable that stores the value of the flag.
+func (f *FlagSet) Int16(name string, value int16, usage string) *int16 {
+	p := new(int16)
+	f.Int16VarP(p, name, "", value, usage)
+	return p
+}
+
+// Like Int16, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) Int16P(name, shorthand string, value int16, usage string) *int16 {
+	p := new(int16)
+	f.Int16VarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// Int16 defines an int16 flag with specified name, default value, and usage string.
+// The return value is the address of an int16 variable that stores the value of the flag.
+func Int16(name string, value int16, usage string) *int16 {
+	return CommandLine.Int16P(name, "", value, usage)
+}
+
+// Like Int16, but accepts a shorthand letter that can be used after a single dash.
+func Int16P(name, shorthand string, value int16, usage string) *int16 {
+	return CommandLine.Int16P(name, shorthand, value, usage)
+}
+
+// GetInt16 returns the int16 value of the named flag.
+func (f *FlagSet) GetInt16(name string) (int16, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return 0, err
+	}
+	tv, ok := v.(*int16Value)
+	if !ok {
+		return 0, fmt.Errorf("flag -%v is not an int16 flag", name)
+	}
+	return int16(*tv), nil
+}
+
+// SetInt16 sets the named int16 flag to value and marks it as set, as Set
+// does, without formatting and parsing the value.
+func (f *FlagSet) SetInt16(name string, value int16) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	tv, ok := flag.Value.(*int16Value)
+	if !ok {
+		return fmt.Errorf("flag -%v is not an int16 flag", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	*tv = int16Value(value)
+	f.markSet(flag, SourceAPI)
+	return nil
+}
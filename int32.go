@@ -15,12 +15,29 @@ func newInt32Value(val int32, p *int32) *int32Value {
 
 func (i *int32Value) Set(s string) error {
 	v, err := strconv.ParseInt(s, 0, 32)
+	if err != nil {
+		return err
+	}
 	*i = int32Value(v)
-	return err
+	return nil
 }
 
 func (i *int32Value) String() string { return fmt.Sprintf("%v", *i) }
 
+func (i *int32Value) Type() string { return "int32" }
+
+func (i *int32Value) clone() Value {
+	v := *i
+	return &v
+}
+
+func (i *int32Value) save() func() {
+	v := *i
+	return func() { *i = v }
+}
+
+func (i *int32Value) Get() interface{} { return int32(*i) }
+
 // Int32Var defines an int32 flag with specified name, default value, and usage string.
 // The argument p points to an int32 variable in which to store the value of the flag.
 func (f *FlagSet) Int32Var(p *int32, name string, value int32, usage string) {
@@ -68,3 +85,35 @@ func Int32(name string, value int32, usage string) *int32 {
 func Int32P(name, shorthand string, value int32, usage string) *int32 {
 	return CommandLine.Int32P(name, shorthand, value, usage)
 }
+
+// GetInt32 returns the int32 value of the named flag.
+func (f *FlagSet) GetInt32(name string) (int32, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return 0, err
+	}
+	tv, ok := v.(*int32Value)
+	if !ok {
+		return 0, fmt.Errorf("flag -%v is not an int32 flag", name)
+	}
+	return int32(*tv), nil
+}
+
+// SetInt32 sets the named int32 flag to value and marks it as set, as Set
+// does, without formatting and parsing the value.
+func (f *FlagSet) SetInt32(name string, value int32) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	tv, ok := flag.Value.(*int32Value)
+	if !ok {
+		return fmt.Errorf("flag -%v is not an int32 flag", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	*tv = int32Value(value)
+	f.markSet(flag, SourceAPI)
+	return nil
+}
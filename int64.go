@@ -15,12 +15,29 @@ func newInt64Value(val int64, p *int64) *int64Value {
 
 func (i *int64Value) Set(s string) error {
 	v, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return err
+	}
 	*i = int64Value(v)
-	return err
+	return nil
 }
 
 func (i *int64Value) String() string { return fmt.Sprintf("%v", *i) }
 
+func (i *int64Value) Type() string { return "int64" }
+
+func (i *int64Value) clone() Value {
+	v := *i
+	return &v
+}
+
+func (i *int64Value) save() func() {
+	v := *i
+	return func() { *i = v }
+}
+
+func (i *int64Value) Get() interface{} { return int64(*i) }
+
 // Int64Var defines an int64 flag with specified name, default value, and usage string.
 // The argument p points to an int64 variable in which to store the value of the flag.
 func (f *FlagSet) Int64Var(p *int64, name string, value int64, usage string) {
@@ -68,3 +85,35 @@ func Int64(name string, value int64, usage string) *int64 {
 func Int64P(name, shorthand string, value int64, usage string) *int64 {
 	return CommandLine.Int64P(name, shorthand, value, usage)
 }
+
+// GetInt64 returns the int64 value of the named flag.
+func (f *FlagSet) GetInt64(name string) (int64, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return 0, err
+	}
+	tv, ok := v.(*int64Value)
+	if !ok {
+		return 0, fmt.Errorf("flag -%v is not an int64 flag", name)
+	}
+	return int64(*tv), nil
+}
+
+// SetInt64 sets the named int64 flag to value and marks it as set, as Set
+// does, without formatting and parsing the value.
+func (f *FlagSet) SetInt64(name string, value int64) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	tv, ok := flag.Value.(*int64Value)
+	if !ok {
+		return fmt.Errorf("flag -%v is not an int64 flag", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	*tv = int64Value(value)
+	f.markSet(flag, SourceAPI)
+	return nil
+}
@@ -0,0 +1,173 @@
+package pflag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// -- []int64 Value
+type int64SliceValue []int64
+
+func newInt64SliceValue(val []int64, p *[]int64) *int64SliceValue {
+	*p = val
+	return (*int64SliceValue)(p)
+}
+
+// Set parses the comma-separated elements of val and appends them to the
+// slice. If any element fails to parse, none of them are appended.
+func (s *int64SliceValue) Set(val string) error {
+	if val == "" {
+		return nil
+	}
+	parts := strings.Split(val, ",")
+	out := make(int64SliceValue, 0, len(parts))
+	for _, part := range parts {
+		v, err := s.parseElem(part)
+		if err != nil {
+			return err
+		}
+		out = append(out, v)
+	}
+	*s = append(*s, out...)
+	return nil
+}
+
+func (s *int64SliceValue) String() string {
+	parts := make([]string, len(*s))
+	for i, v := range *s {
+		parts[i] = fmt.Sprintf("%d", v)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+func (s *int64SliceValue) Type() string { return "int64Slice" }
+
+func (s *int64SliceValue) clone() Value {
+	v := append(int64SliceValue(nil), *s...)
+	return &v
+}
+
+func (s *int64SliceValue) save() func() {
+	v := append(int64SliceValue(nil), *s...)
+	return func() { *s = append(int64SliceValue(nil), v...) }
+}
+
+func (s *int64SliceValue) Get() interface{} { return []int64(*s) }
+
+// parseElem parses a single element of the slice.
+func (s *int64SliceValue) parseElem(val string) (int64, error) {
+	v, err := strconv.ParseInt(strings.TrimSpace(val), 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid element %q: %v", val, err)
+	}
+	return v, nil
+}
+
+// Append parses val as a single element and adds it to the slice.
+func (s *int64SliceValue) Append(val string) error {
+	v, err := s.parseElem(val)
+	if err != nil {
+		return err
+	}
+	*s = append(*s, v)
+	return nil
+}
+
+// Replace sets the slice to the parsed vals. If any fails to parse, the
+// slice is unchanged.
+func (s *int64SliceValue) Replace(vals []string) error {
+	out := make(int64SliceValue, 0, len(vals))
+	for _, val := range vals {
+		v, err := s.parseElem(val)
+		if err != nil {
+			return err
+		}
+		out = append(out, v)
+	}
+	*s = out
+	return nil
+}
+
+// GetSlice returns the elements formatted as strings.
+func (s *int64SliceValue) GetSlice() []string {
+	out := make([]string, len(*s))
+	for i, v := range *s {
+		out[i] = strconv.FormatInt(v, 10)
+	}
+	return out
+}
+
+func (s *int64SliceValue) restore(val string) error {
+	var v int64SliceValue
+	if err := v.Set(strings.TrimSuffix(strings.TrimPrefix(val, "["), "]")); err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// Int64SliceVar defines a []int64 flag with specified name, default value, and usage string.
+// Each use of the flag appends its comma-separated elements to the slice;
+// the first use replaces the default.
+// The argument p points to a []int64 variable in which to store the value of the flag.
+func (f *FlagSet) Int64SliceVar(p *[]int64, name string, value []int64, usage string) {
+	f.VarP(newInt64SliceValue(value, p), name, "", usage)
+}
+
+// Like Int64SliceVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) Int64SliceVarP(p *[]int64, name, shorthand string, value []int64, usage string) {
+	f.VarP(newInt64SliceValue(value, p), name, shorthand, usage)
+}
+
+// Int64SliceVar defines a []int64 flag with specified name, default value, and usage string.
+// Each use of the flag appends its comma-separated elements to the slice;
+// the first use replaces the default.
+// The argument p points to a []int64 variable in which to store the value of the flag.
+func Int64SliceVar(p *[]int64, name string, value []int64, usage string) {
+	CommandLine.VarP(newInt64SliceValue(value, p), name, "", usage)
+}
+
+// Like Int64SliceVar, but accepts a shorthand letter that can be used after a single dash.
+func Int64SliceVarP(p *[]int64, name, shorthand string, value []int64, usage string) {
+	CommandLine.VarP(newInt64SliceValue(value, p), name, shorthand, usage)
+}
+
+// Int64Slice defines a []int64 flag with specified name, default value, and usage string.
+// The return value is the address of a []int64 variable that stores the value of the flag.
+func (f *FlagSet) Int64Slice(name string, value []int64, usage string) *[]int64 {
+	p := new([]int64)
+	f.Int64SliceVarP(p, name, "", value, usage)
+	return p
+}
+
+// Like Int64Slice, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) Int64SliceP(name, shorthand string, value []int64, usage string) *[]int64 {
+	p := new([]int64)
+	f.Int64SliceVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// Int64Slice defines a []int64 flag with specified name, default value, and usage string.
+// The return value is the address of a []int64 variable that stores the value of the flag.
+func Int64Slice(name string, value []int64, usage string) *[]int64 {
+	return CommandLine.Int64SliceP(name, "", value, usage)
+}
+
+// Like Int64Slice, but accepts a shorthand letter that can be used after a single dash.
+func Int64SliceP(name, shorthand string, value []int64, usage string) *[]int64 {
+	return CommandLine.Int64SliceP(name, shorthand, value, usage)
+}
+
+// GetInt64Slice returns a copy of the []int64 value of the named flag.
+func (f *FlagSet) GetInt64Slice(name string) ([]int64, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return nil, err
+	}
+	sv, ok := v.(*int64SliceValue)
+	if !ok {
+		return nil, fmt.Errorf("flag -%v is not an int64 slice flag", name)
+	}
+	return append([]int64(nil), *sv...), nil
+}
@@ -15,12 +15,29 @@ func newInt8Value(val int8, p *int8) *int8Value {
 
 func (i *int8Value) Set(s string) error {
 	v, err := strconv.ParseInt(s, 0, 8)
+	if err != nil {
+		return err
+	}
 	*i = int8Value(v)
-	return err
+	return nil
 }
 
 func (i *int8Value) String() string { return fmt.Sprintf("%v", *i) }
 
+func (i *int8Value) Type() string { return "int8" }
+
+func (i *int8Value) clone() Value {
+	v := *i
+	return &v
+}
+
+func (i *int8Value) save() func() {
+	v := *i
+	return func() { *i = v }
+}
+
+func (i *int8Value) Get() interface{} { return int8(*i) }
+
 // Int8Var defines an int8 flag with specified name, default value, and usage string.
 // The argument p points to an int8 variable in which to store the value of the flag.
 func (f *FlagSet) Int8Var(p *int8, name string, value int8, usage string) {
@@ -68,3 +85,35 @@ func Int8(name string, value int8, usage string) *int8 {
 func Int8P(name, shorthand string, value int8, usage string) *int8 {
 	return CommandLine.Int8P(name, shorthand, value, usage)
 }
+
+// GetInt8 returns the int8 value of the named flag.
+func (f *FlagSet) GetInt8(name string) (int8, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return 0, err
+	}
+	tv, ok := v.(*int8Value)
+	if !ok {
+		return 0, fmt.Errorf("flag -%v is not an int8 flag", name)
+	}
+	return int8(*tv), nil
+}
+
+// SetInt8 sets the named int8 flag to value and marks it as set, as Set
+// does, without formatting and parsing the value.
+func (f *FlagSet) SetInt8(name string, value int8) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	tv, ok := flag.Value.(*int8Value)
+	if !ok {
+		return fmt.Errorf("flag -%v is not an int8 flag", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	*tv = int8Value(value)
+	f.markSet(flag, SourceAPI)
+	return nil
+}
@@ -0,0 +1,173 @@
+package pflag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// -- []int Value
+type intSliceValue []int
+
+func newIntSliceValue(val []int, p *[]int) *intSliceValue {
+	*p = val
+	return (*intSliceValue)(p)
+}
+
+// Set parses the comma-separated elements of val and appends them to the
+// slice. If any element fails to parse, none of them are appended.
+func (s *intSliceValue) Set(val string) error {
+	if val == "" {
+		return nil
+	}
+	parts := strings.Split(val, ",")
+	out := make(intSliceValue, 0, len(parts))
+	for _, part := range parts {
+		v, err := s.parseElem(part)
+		if err != nil {
+			return err
+		}
+		out = append(out, v)
+	}
+	*s = append(*s, out...)
+	return nil
+}
+
+func (s *intSliceValue) String() string {
+	parts := make([]string, len(*s))
+	for i, v := range *s {
+		parts[i] = fmt.Sprintf("%d", v)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+func (s *intSliceValue) Type() string { return "intSlice" }
+
+func (s *intSliceValue) clone() Value {
+	v := append(intSliceValue(nil), *s...)
+	return &v
+}
+
+func (s *intSliceValue) save() func() {
+	v := append(intSliceValue(nil), *s...)
+	return func() { *s = append(intSliceValue(nil), v...) }
+}
+
+func (s *intSliceValue) Get() interface{} { return []int(*s) }
+
+// parseElem parses a single element of the slice.
+func (s *intSliceValue) parseElem(val string) (int, error) {
+	v, err := strconv.ParseInt(strings.TrimSpace(val), 0, 0)
+	if err != nil {
+		return 0, fmt.Errorf("invalid element %q: %v", val, err)
+	}
+	return int(v), nil
+}
+
+// Append parses val as a single element and adds it to the slice.
+func (s *intSliceValue) Append(val string) error {
+	v, err := s.parseElem(val)
+	if err != nil {
+		return err
+	}
+	*s = append(*s, v)
+	return nil
+}
+
+// Replace sets the slice to the parsed vals. If any fails to parse, the
+// slice is unchanged.
+func (s *intSliceValue) Replace(vals []string) error {
+	out := make(intSliceValue, 0, len(vals))
+	for _, val := range vals {
+		v, err := s.parseElem(val)
+		if err != nil {
+			return err
+		}
+		out = append(out, v)
+	}
+	*s = out
+	return nil
+}
+
+// GetSlice returns the elements formatted as strings.
+func (s *intSliceValue) GetSlice() []string {
+	out := make([]string, len(*s))
+	for i, v := range *s {
+		out[i] = strconv.Itoa(v)
+	}
+	return out
+}
+
+func (s *intSliceValue) restore(val string) error {
+	var v intSliceValue
+	if err := v.Set(strings.TrimSuffix(strings.TrimPrefix(val, "["), "]")); err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// IntSliceVar defines a []int flag with specified name, default value, and usage string.
+// Each use of the flag appends its comma-separated elements to the slice;
+// the first use replaces the default.
+// The argument p points to a []int variable in which to store the value of the flag.
+func (f *FlagSet) IntSliceVar(p *[]int, name string, value []int, usage string) {
+	f.VarP(newIntSliceValue(value, p), name, "", usage)
+}
+
+// Like IntSliceVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) IntSliceVarP(p *[]int, name, shorthand string, value []int, usage string) {
+	f.VarP(newIntSliceValue(value, p), name, shorthand, usage)
+}
+
+// IntSliceVar defines a []int flag with specified name, default value, and usage string.
+// Each use of the flag appends its comma-separated elements to the slice;
+// the first use replaces the default.
+// The argument p points to a []int variable in which to store the value of the flag.
+func IntSliceVar(p *[]int, name string, value []int, usage string) {
+	CommandLine.VarP(newIntSliceValue(value, p), name, "", usage)
+}
+
+// Like IntSliceVar, but accepts a shorthand letter that can be used after a single dash.
+func IntSliceVarP(p *[]int, name, shorthand string, value []int, usage string) {
+	CommandLine.VarP(newIntSliceValue(value, p), name, shorthand, usage)
+}
+
+// IntSlice defines a []int flag with specified name, default value, and usage string.
+// The return value is the address of a []int variable that stores the value of the flag.
+func (f *FlagSet) IntSlice(name string, value []int, usage string) *[]int {
+	p := new([]int)
+	f.IntSliceVarP(p, name, "", value, usage)
+	return p
+}
+
+// Like IntSlice, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) IntSliceP(name, shorthand string, value []int, usage string) *[]int {
+	p := new([]int)
+	f.IntSliceVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// IntSlice defines a []int flag with specified name, default value, and usage string.
+// The return value is the address of a []int variable that stores the value of the flag.
+func IntSlice(name string, value []int, usage string) *[]int {
+	return CommandLine.IntSliceP(name, "", value, usage)
+}
+
+// Like IntSlice, but accepts a shorthand letter that can be used after a single dash.
+func IntSliceP(name, shorthand string, value []int, usage string) *[]int {
+	return CommandLine.IntSliceP(name, shorthand, value, usage)
+}
+
+// GetIntSlice returns a copy of the []int value of the named flag.
+func (f *FlagSet) GetIntSlice(name string) ([]int, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return nil, err
+	}
+	sv, ok := v.(*intSliceValue)
+	if !ok {
+		return nil, fmt.Errorf("flag -%v is not an int slice flag", name)
+	}
+	return append([]int(nil), *sv...), nil
+}
@@ -0,0 +1,96 @@
+package pflag
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIntSlice(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	ports := f.IntSliceP("ports", "p", nil, "ports")
+	if err := f.Parse([]string{"--ports", "80,443", "-p", "8080"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !reflect.DeepEqual(*ports, []int{80, 443, 8080}) {
+		t.Fatal("expected [80 443 8080] but got", *ports)
+	}
+	if s := f.Lookup("ports").Value.String(); s != "[80,443,8080]" {
+		t.Fatalf("expected %q but got %q", "[80,443,8080]", s)
+	}
+}
+
+func TestIntSliceInvalid(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	ports := f.IntSlice("ports", nil, "ports")
+	err := f.Parse([]string{"--ports", "80,abc"})
+	if err == nil || !strings.Contains(err.Error(), `"abc"`) {
+		t.Fatal("expected an error naming the bad element; got", err)
+	}
+	if len(*ports) != 0 {
+		t.Fatal("expected nothing appended on error but got", *ports)
+	}
+}
+
+func TestInt64Slice(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	sizes := f.Int64Slice("sizes", nil, "sizes")
+	if err := f.Parse([]string{"--sizes=1,8589934592", "--sizes=-3"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !reflect.DeepEqual(*sizes, []int64{1, 8589934592, -3}) {
+		t.Fatal("expected [1 8589934592 -3] but got", *sizes)
+	}
+	if err := f.Parse([]string{"--sizes=1,x"}); err == nil {
+		t.Fatal("expected an error for an invalid element")
+	}
+}
+
+func TestIntSliceInvalidKeepsDefault(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	ports := f.IntSlice("ports", []int{80}, "ports")
+	if err := f.Parse([]string{"--ports=x"}); err == nil {
+		t.Fatal("expected an error for an invalid element")
+	}
+	if !reflect.DeepEqual(*ports, []int{80}) {
+		t.Fatal("expected the default [80] to be kept but got", *ports)
+	}
+	if err := f.Parse([]string{"--ports=443"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !reflect.DeepEqual(*ports, []int{443}) {
+		t.Fatal("expected [443] but got", *ports)
+	}
+}
+
+func TestGetIntSlices(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	ports := f.IntSlice("ports", nil, "")
+	sizes := f.Int64Slice("sizes", nil, "")
+	f.String("name", "", "")
+	if err := f.Parse([]string{"--ports=80,443", "--sizes=1,2"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	gotPorts, err := f.GetIntSlice("ports")
+	if err != nil || !reflect.DeepEqual(gotPorts, []int{80, 443}) {
+		t.Fatalf("expected [80 443]; got %v, %v", gotPorts, err)
+	}
+	gotSizes, err := f.GetInt64Slice("sizes")
+	if err != nil || !reflect.DeepEqual(gotSizes, []int64{1, 2}) {
+		t.Fatalf("expected [1 2]; got %v, %v", gotSizes, err)
+	}
+	gotPorts[0], gotSizes[0] = 0, 0
+	if (*ports)[0] != 80 || (*sizes)[0] != 1 {
+		t.Errorf("expected copies; flags now hold %v and %v", *ports, *sizes)
+	}
+	if _, err := f.GetIntSlice("sizes"); err == nil {
+		t.Error("expected an error getting an int64 slice flag as an int slice")
+	}
+	if _, err := f.GetInt64Slice("name"); err == nil {
+		t.Error("expected an error getting a string flag as an int64 slice")
+	}
+}
@@ -22,6 +22,19 @@ func (i *ipValue) Set(s string) error {
 	*i = ipValue(ip)
 	return nil
 }
+
+func (i *ipValue) Type() string { return "ip" }
+
+func (i *ipValue) clone() Value {
+	v := append(ipValue(nil), *i...)
+	return &v
+}
+
+func (i *ipValue) save() func() {
+	v := append(ipValue(nil), *i...)
+	return func() { *i = append(ipValue(nil), v...) }
+}
+
 func (i *ipValue) Get() interface{} {
 	return net.IP(*i)
 }
@@ -73,3 +86,16 @@ func IP(name string, value net.IP, usage string) *net.IP {
 func IPP(name, shorthand string, value net.IP, usage string) *net.IP {
 	return CommandLine.IPP(name, shorthand, value, usage)
 }
+
+// GetIP returns the net.IP value of the named flag.
+func (f *FlagSet) GetIP(name string) (net.IP, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return nil, err
+	}
+	tv, ok := v.(*ipValue)
+	if !ok {
+		return nil, fmt.Errorf("flag -%v is not an IP flag", name)
+	}
+	return net.IP(*tv), nil
+}
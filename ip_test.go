@@ -0,0 +1,51 @@
+package pflag
+
+import (
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestIP(t *testing.T) {
+	tests := []struct {
+		arg      string
+		expected string
+	}{
+		{"192.168.0.1", "192.168.0.1"},
+		{"2001:db8::1", "2001:db8::1"},
+		{"::ffff:10.0.0.1", "10.0.0.1"},
+	}
+	for _, test := range tests {
+		f := NewFlagSet("test", ContinueOnError)
+		addr := f.IPP("addr", "a", net.ParseIP("127.0.0.1"), "address")
+		if err := f.Parse([]string{"--addr", test.arg}); err != nil {
+			t.Errorf("%s: expected no error; got %v", test.arg, err)
+			continue
+		}
+		if addr.String() != test.expected {
+			t.Errorf("%s: expected %s but got %s", test.arg, test.expected, addr)
+		}
+		ip, err := f.GetIP("addr")
+		if err != nil || !ip.Equal(net.ParseIP(test.expected)) {
+			t.Errorf("%s: expected GetIP to return %s but got %v, %v", test.arg, test.expected, ip, err)
+		}
+	}
+}
+
+func TestIPInvalid(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	addr := f.IP("addr", net.ParseIP("127.0.0.1"), "address")
+	err := f.Parse([]string{"--addr=300.1.2.3"})
+	if err == nil || !strings.Contains(err.Error(), "300.1.2.3") {
+		t.Fatal("expected an error naming the bad address; got", err)
+	}
+	if addr.String() != "127.0.0.1" {
+		t.Fatal("expected the address to be unchanged but got", addr)
+	}
+	f.String("name", "", "")
+	if _, err := f.GetIP("name"); err == nil {
+		t.Fatal("expected an error getting a string flag as an IP")
+	}
+}
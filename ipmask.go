@@ -22,6 +22,19 @@ func (i *ipMaskValue) Set(s string) error {
 	*i = ipMaskValue(ip)
 	return nil
 }
+
+func (i *ipMaskValue) Type() string { return "ipMask" }
+
+func (i *ipMaskValue) clone() Value {
+	v := append(ipMaskValue(nil), *i...)
+	return &v
+}
+
+func (i *ipMaskValue) save() func() {
+	v := append(ipMaskValue(nil), *i...)
+	return func() { *i = append(ipMaskValue(nil), v...) }
+}
+
 func (i *ipMaskValue) Get() interface{} {
 	return net.IPMask(*i)
 }
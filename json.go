@@ -0,0 +1,200 @@
+package pflag
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteValuesJSON writes a JSON object mapping the name of each flag to its
+// current value. Values are written with their Go types where JSON has a
+// matching one, so ints are numbers, bools are booleans and slices are
+// arrays. Durations, URLs and binary values, which would otherwise come out
+// as nanoseconds, objects or base64, are written as their String form, as
+// are Values that do not implement Getter. Infinite and NaN floats, which
+// JSON cannot represent, are written as the strings "+Inf", "-Inf" and
+// "NaN". A flag with only a shorthand is
+// written under its shorthand with a leading dash, such as "-q", so that it
+// is not mistaken for a long flag.
+func (f *FlagSet) WriteValuesJSON(w io.Writer) error {
+	values := make(map[string]interface{}, len(f.formal))
+	for name, flag := range f.formal {
+		if f.shorthandOnly[flag] {
+			name = "-" + name
+		}
+		values[name] = jsonValue(flag.Value)
+	}
+	return json.NewEncoder(w).Encode(values)
+}
+
+// WriteValuesJSON writes the values of the command-line flags to w as a JSON object.
+func WriteValuesJSON(w io.Writer) error {
+	return CommandLine.WriteValuesJSON(w)
+}
+
+// jsonValue returns the value to encode as JSON for v.
+func jsonValue(v Value) interface{} {
+	g, ok := v.(Getter)
+	if !ok {
+		return v.String()
+	}
+	switch x := g.Get().(type) {
+	case time.Duration, TTL, net.IPMask, []byte:
+		return v.String()
+	case []time.Duration:
+		s := make([]string, len(x))
+		for i, d := range x {
+			s[i] = d.String()
+		}
+		return s
+	case *url.URL:
+		if x == nil {
+			return nil
+		}
+		return x.String()
+	case float32:
+		if f := float64(x); math.IsInf(f, 0) || math.IsNaN(f) {
+			return strconv.FormatFloat(f, 'g', -1, 32)
+		}
+		return x
+	case float64:
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			return strconv.FormatFloat(x, 'g', -1, 64)
+		}
+		return x
+	default:
+		return x
+	}
+}
+
+// ApplyJSON sets flags from a flat JSON object read from r, mapping flag
+// names to values. Strings are used as they are, numbers and booleans are
+// converted to their text form, and null leaves a flag alone. An array
+// replaces the elements of a slice flag and is joined with commas for
+// any other flag. A flag with only a shorthand may also be given as the
+// shorthand with a leading dash, as WriteValuesJSON writes it. Keys that
+// do not name a flag are ignored unless
+// SetStrictJSON is on, in which case nothing is set and they are reported
+// in the error.
+//
+// Values applied this way prime the flags without counting as set: Changed
+// stays false, they do not satisfy MarkRequired or make an immutable flag
+// unsettable, and a later Parse overrides them. Flags that have already
+// been set are left alone, so the command line takes precedence whichever
+// of Parse and ApplyJSON is called first.
+func (f *FlagSet) ApplyJSON(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var values map[string]interface{}
+	if err := dec.Decode(&values); err != nil {
+		return fmt.Errorf("invalid JSON: %v", err)
+	}
+	names := make([]string, 0, len(values))
+	var unknown []string
+	for name := range values {
+		if _, ok := f.jsonLookup(name); ok {
+			names = append(names, name)
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	if f.strictJSON && len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown flags %v in JSON", unknown)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		flag, _ := f.jsonLookup(name)
+		if _, set := f.actual[flag.Name]; set || f.commandLineOnly[flag] {
+			continue
+		}
+		if err := f.applyJSONValue(flag, values[name]); err != nil {
+			return fmt.Errorf("invalid value for flag -%s in JSON: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// ApplyJSON sets command-line flags that have not already been set from a JSON object read from r.
+func ApplyJSON(r io.Reader) error {
+	return CommandLine.ApplyJSON(r)
+}
+
+// jsonLookup finds the flag for the JSON key name, which is either a flag
+// name or, for a flag with only a shorthand, the shorthand after a dash.
+func (f *FlagSet) jsonLookup(name string) (*Flag, bool) {
+	if len(name) == 2 && name[0] == '-' {
+		flag, ok := f.shorthands[name[1]]
+		return flag, ok && f.shorthandOnly[flag]
+	}
+	return f.lookup(name)
+}
+
+// SetStrictJSON controls whether ApplyJSON rejects keys that do not name a
+// flag. It is off by default.
+func (f *FlagSet) SetStrictJSON(strict bool) {
+	f.strictJSON = strict
+}
+
+// applyJSONValue sets flag from the decoded JSON value v.
+func (f *FlagSet) applyJSONValue(flag *Flag, v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		elems := make([]string, len(x))
+		for i, e := range x {
+			s, err := jsonString(e)
+			if err != nil {
+				return err
+			}
+			elems[i] = s
+		}
+		sv, ok := flag.Value.(SliceValue)
+		if !ok {
+			return f.prime(flag, strings.Join(elems, ","))
+		}
+		if err := f.checkMutable(flag); err != nil {
+			return err
+		}
+		if f.expandEnv {
+			for i := range elems {
+				elems[i] = os.ExpandEnv(elems[i])
+			}
+		}
+		if err := sv.Replace(elems); err != nil {
+			return err
+		}
+		flag.Source = SourceConfig
+		f.notify(flag)
+		return nil
+	default:
+		s, err := jsonString(x)
+		if err != nil {
+			return err
+		}
+		return f.prime(flag, s)
+	}
+}
+
+// jsonString returns the flag value text for a decoded JSON scalar.
+func jsonString(v interface{}) (string, error) {
+	switch x := v.(type) {
+	case string:
+		return x, nil
+	case json.Number:
+		return x.String(), nil
+	case bool:
+		return strconv.FormatBool(x), nil
+	default:
+		return "", fmt.Errorf("unsupported JSON value %v", v)
+	}
+}
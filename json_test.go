@@ -0,0 +1,224 @@
+package pflag
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type customValue string
+
+func (c *customValue) String() string     { return "custom:" + string(*c) }
+func (c *customValue) Set(s string) error { *c = customValue(s); return nil }
+
+func TestWriteValuesJSON(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Int("count", 3, "")
+	f.Bool("verbose", false, "")
+	f.String("name", "gopher", "")
+	f.StringSlice("tag", []string{"a", "b"}, "")
+	f.IntSlice("port", []int{80, 443}, "")
+	f.Duration("timeout", 1500*time.Millisecond, "")
+	f.BytesHex("key", []byte{0xab}, "")
+	c := customValue("x")
+	f.Var(&c, "custom", "")
+	if err := f.Parse([]string{"--verbose", "--count=5"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+
+	var buf bytes.Buffer
+	if err := f.WriteValuesJSON(&buf); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("expected valid JSON, got %q: %v", buf.String(), err)
+	}
+	want := map[string]interface{}{
+		"count":   5.0,
+		"verbose": true,
+		"name":    "gopher",
+		"tag":     []interface{}{"a", "b"},
+		"port":    []interface{}{80.0, 443.0},
+		"timeout": "1.5s",
+		"key":     "AB",
+		"custom":  "custom:x",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v but got %v", want, got)
+	}
+}
+
+func TestWriteValuesJSONNonFinite(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Float64("max", math.Inf(1), "")
+	f.Float32("min", float32(math.Inf(-1)), "")
+	f.Float64("ratio", math.NaN(), "")
+	f.Float64("scale", 1.5, "")
+	var buf bytes.Buffer
+	if err := f.WriteValuesJSON(&buf); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	want := `{"max":"+Inf","min":"-Inf","ratio":"NaN","scale":1.5}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	g := NewFlagSet("test", ContinueOnError)
+	max := g.Float64("max", 0, "")
+	min := g.Float32("min", 0, "")
+	ratio := g.Float64("ratio", 0, "")
+	if err := g.ApplyJSON(&buf); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !math.IsInf(*max, 1) || !math.IsInf(float64(*min), -1) || !math.IsNaN(*ratio) {
+		t.Errorf("expected the values to round-trip, got max=%v min=%v ratio=%v", *max, *min, *ratio)
+	}
+}
+
+func TestValuesJSONShorthandOnly(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.BoolP("", "q", false, "")
+	f.IntP("level", "l", 0, "")
+	if err := f.Parse([]string{"-q", "-l", "2"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	var buf bytes.Buffer
+	if err := f.WriteValuesJSON(&buf); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if got, want := buf.String(), `{"-q":true,"level":2}`+"\n"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	g := NewFlagSet("test", ContinueOnError)
+	q := g.BoolP("", "q", false, "")
+	g.Int("l", 0, "")
+	g.SetStrictJSON(true)
+	if err := g.ApplyJSON(strings.NewReader(`{"-q": true}`)); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !*q {
+		t.Error("expected -q to be applied from its dashed key")
+	}
+	if err := g.ApplyJSON(strings.NewReader(`{"-l": 1}`)); err == nil {
+		t.Error("expected a dashed key to be rejected for a long flag")
+	}
+}
+
+func TestApplyJSON(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	count := f.Int("count", 3, "")
+	verbose := f.Bool("verbose", false, "")
+	name := f.String("name", "gopher", "")
+	ratio := f.Float64("ratio", 0, "")
+	tags := f.StringSlice("tag", []string{"default"}, "")
+	ports := f.IntSlice("port", nil, "")
+	config := `{"count": 5, "verbose": true, "name": "config", "ratio": 1.5,
+		"tag": ["a", "b"], "port": [80, 443], "unknown": 1}`
+	if err := f.ApplyJSON(strings.NewReader(config)); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if *count != 5 || !*verbose || *name != "config" || *ratio != 1.5 {
+		t.Errorf("got count=%d verbose=%v name=%q ratio=%v", *count, *verbose, *name, *ratio)
+	}
+	if !reflect.DeepEqual(*tags, []string{"a", "b"}) || !reflect.DeepEqual(*ports, []int{80, 443}) {
+		t.Errorf("got tag=%v port=%v", *tags, *ports)
+	}
+	if f.Changed("count") {
+		t.Error("expected count not to count as changed after ApplyJSON")
+	}
+	if src := f.Lookup("count").Source; src != SourceConfig {
+		t.Errorf("expected source %v; got %v", SourceConfig, src)
+	}
+
+	if err := f.Parse([]string{"--count=7", "--tag=c"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if *count != 7 || !reflect.DeepEqual(*tags, []string{"c"}) {
+		t.Errorf("expected the command line to override; got count=%d tag=%v", *count, *tags)
+	}
+	if *name != "config" {
+		t.Errorf("expected name from JSON to remain; got %q", *name)
+	}
+}
+
+func TestApplyJSONAfterParse(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	count := f.Int("count", 3, "")
+	name := f.String("name", "gopher", "")
+	if err := f.Parse([]string{"--count=7"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if err := f.ApplyJSON(strings.NewReader(`{"count": 5, "name": "config"}`)); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if *count != 7 || *name != "config" {
+		t.Errorf("expected count=7 name=config; got count=%d name=%q", *count, *name)
+	}
+}
+
+func TestApplyJSONStrict(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	count := f.Int("count", 3, "")
+	f.SetStrictJSON(true)
+	err := f.ApplyJSON(strings.NewReader(`{"count": 5, "zeta": 1, "alpha": "x"}`))
+	if err == nil {
+		t.Fatal("expected an error for unknown keys")
+	}
+	if want := "unknown flags [alpha zeta] in JSON"; err.Error() != want {
+		t.Errorf("expected %q; got %q", want, err)
+	}
+	if *count != 3 {
+		t.Errorf("expected nothing to be set; got count=%d", *count)
+	}
+}
+
+func TestApplyJSONInvalid(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.Int("count", 3, "")
+	for _, config := range []string{`{"count": "x"}`, `{"count": 1.5}`, `{"count": {"a": 1}}`, `[1]`} {
+		if err := f.ApplyJSON(strings.NewReader(config)); err == nil {
+			t.Errorf("expected an error for %s", config)
+		}
+	}
+}
+
+func TestApplyJSONCommandLineOnly(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	token := f.String("token", "", "")
+	f.MarkCommandLineOnly("token")
+	if err := f.ApplyJSON(strings.NewReader(`{"token": "secret"}`)); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if *token != "" {
+		t.Errorf("expected command-line-only flag to ignore JSON; got %q", *token)
+	}
+}
+
+func TestApplyJSONEffectiveString(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.String("name", "def", "")
+	f.StringSlice("tag", nil, "")
+	changes := make(chan FlagChange, 2)
+	f.SetChangeChannel(changes)
+	if err := f.ApplyJSON(strings.NewReader(`{"name": "cfg", "tag": ["a"]}`)); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if v, err := f.EffectiveString("name"); err != nil || v != "cfg" {
+		t.Errorf("expected the JSON value cfg; got %q, %v", v, err)
+	}
+	close(changes)
+	var got []FlagChange
+	for change := range changes {
+		got = append(got, change)
+	}
+	want := []FlagChange{{"name", "cfg"}, {"tag", "[a]"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected notifications %v; got %v", want, got)
+	}
+}
@@ -0,0 +1,151 @@
+package pflag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LogLevels is a default log level plus per-module overrides, as parsed
+// from a value like "info,http=debug,db=warn".
+type LogLevels struct {
+	Default int            // level for modules without an override
+	Modules map[string]int // overrides, by module name
+}
+
+// -- LogLevels Value
+type logLevelsValue struct {
+	value  *LogLevels
+	levels map[string]int
+}
+
+func newLogLevelsValue(val LogLevels, levels map[string]int, p *LogLevels) *logLevelsValue {
+	*p = val
+	return &logLevelsValue{value: p, levels: levels}
+}
+
+func (l *logLevelsValue) level(name string) (int, error) {
+	level, ok := l.levels[name]
+	if !ok {
+		names := make([]string, 0, len(l.levels))
+		for n := range l.levels {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return 0, fmt.Errorf("unknown log level %q, expected one of %s", name, strings.Join(names, ", "))
+	}
+	return level, nil
+}
+
+// levelName returns the name for level, choosing the first in sorted order
+// if several names share it so that String is deterministic.
+func (l *logLevelsValue) levelName(level int) string {
+	name, found := "", false
+	for n, v := range l.levels {
+		if v == level && (!found || n < name) {
+			name, found = n, true
+		}
+	}
+	if !found {
+		return fmt.Sprintf("%d", level)
+	}
+	return name
+}
+
+// Set parses a comma-separated list of at most one bare level, which
+// becomes the default, and any number of module=level overrides.
+func (l *logLevelsValue) Set(s string) error {
+	v := LogLevels{Default: l.value.Default, Modules: make(map[string]int)}
+	seenDefault := false
+	for _, token := range strings.Split(s, ",") {
+		token = strings.TrimSpace(token)
+		kv := strings.SplitN(token, "=", 2)
+		if len(kv) == 1 {
+			if seenDefault {
+				return fmt.Errorf("more than one default log level in %q", s)
+			}
+			level, err := l.level(token)
+			if err != nil {
+				return err
+			}
+			v.Default, seenDefault = level, true
+			continue
+		}
+		if kv[0] == "" {
+			return fmt.Errorf("missing module name in %q", token)
+		}
+		level, err := l.level(kv[1])
+		if err != nil {
+			return err
+		}
+		v.Modules[kv[0]] = level
+	}
+	*l.value = v
+	return nil
+}
+
+func (l *logLevelsValue) String() string {
+	parts := []string{l.levelName(l.value.Default)}
+	modules := make([]string, 0, len(l.value.Modules))
+	for m := range l.value.Modules {
+		modules = append(modules, m)
+	}
+	sort.Strings(modules)
+	for _, m := range modules {
+		parts = append(parts, m+"="+l.levelName(l.value.Modules[m]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (l *logLevelsValue) Type() string { return "logLevels" }
+
+func (l *logLevelsValue) clone() Value {
+	v := *l.value
+	return &logLevelsValue{value: &v, levels: l.levels}
+}
+
+func (l *logLevelsValue) save() func() {
+	v := *l.value
+	return func() { *l.value = v }
+}
+
+func (l *logLevelsValue) Get() interface{} { return *l.value }
+
+// LogLevelsVar defines a LogLevels flag with specified name, default value, level names, and usage string.
+// The flag accepts a value like "info,http=debug" where the bare level is the default and each
+// module=level pair is an override; levels maps the accepted level names to their values.
+// The argument p points to a LogLevels variable in which to store the value of the flag.
+func (f *FlagSet) LogLevelsVar(p *LogLevels, name string, value LogLevels, levels map[string]int, usage string) {
+	f.VarP(newLogLevelsValue(value, levels, p), name, "", usage)
+}
+
+// Like LogLevelsVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) LogLevelsVarP(p *LogLevels, name, shorthand string, value LogLevels, levels map[string]int, usage string) {
+	f.VarP(newLogLevelsValue(value, levels, p), name, shorthand, usage)
+}
+
+// LogLevelsVar defines a LogLevels flag with specified name, default value, level names, and usage string.
+// The flag accepts a value like "info,http=debug" where the bare level is the default and each
+// module=level pair is an override; levels maps the accepted level names to their values.
+// The argument p points to a LogLevels variable in which to store the value of the flag.
+func LogLevelsVar(p *LogLevels, name string, value LogLevels, levels map[string]int, usage string) {
+	CommandLine.VarP(newLogLevelsValue(value, levels, p), name, "", usage)
+}
+
+// Like LogLevelsVar, but accepts a shorthand letter that can be used after a single dash.
+func LogLevelsVarP(p *LogLevels, name, shorthand string, value LogLevels, levels map[string]int, usage string) {
+	CommandLine.VarP(newLogLevelsValue(value, levels, p), name, shorthand, usage)
+}
+
+// GetLogLevels returns the value of the named LogLevels flag.
+func (f *FlagSet) GetLogLevels(name string) (LogLevels, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return LogLevels{}, err
+	}
+	lv, ok := v.(*logLevelsValue)
+	if !ok {
+		return LogLevels{}, fmt.Errorf("flag -%v is not a log levels flag", name)
+	}
+	return *lv.value, nil
+}
@@ -0,0 +1,60 @@
+package pflag
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+var testLevels = map[string]int{"debug": 0, "info": 1, "warn": 2, "error": 3}
+
+func setUpLogLevelsFlagSet(levels *LogLevels) *FlagSet {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	f.LogLevelsVar(levels, "log", LogLevels{Default: 1}, testLevels, "log levels")
+	return f
+}
+
+func TestLogLevelsDefaultOnly(t *testing.T) {
+	var levels LogLevels
+	f := setUpLogLevelsFlagSet(&levels)
+	if err := f.Parse([]string{"--log=warn"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if levels.Default != 2 || len(levels.Modules) != 0 {
+		t.Fatal("expected default warn and no overrides but got", levels)
+	}
+	if s := f.Lookup("log").Value.String(); s != "warn" {
+		t.Fatalf("expected %q but got %q", "warn", s)
+	}
+}
+
+func TestLogLevelsOverrides(t *testing.T) {
+	var levels LogLevels
+	f := setUpLogLevelsFlagSet(&levels)
+	if err := f.Parse([]string{"--log=info,http=debug,db=warn"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	want := LogLevels{Default: 1, Modules: map[string]int{"http": 0, "db": 2}}
+	if !reflect.DeepEqual(levels, want) {
+		t.Fatalf("expected %v but got %v", want, levels)
+	}
+	if s := f.Lookup("log").Value.String(); s != "info,db=warn,http=debug" {
+		t.Fatalf("expected %q but got %q", "info,db=warn,http=debug", s)
+	}
+	got, err := f.GetLogLevels("log")
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected GetLogLevels to return %v, got %v, %v", want, got, err)
+	}
+}
+
+func TestLogLevelsUnknown(t *testing.T) {
+	var levels LogLevels
+	f := setUpLogLevelsFlagSet(&levels)
+	if err := f.Parse([]string{"--log=info,http=verbose"}); err == nil {
+		t.Fatal("expected an error for an unknown level")
+	}
+	if levels.Default != 1 || len(levels.Modules) != 0 {
+		t.Fatal("expected the value to be unchanged but got", levels)
+	}
+}
@@ -0,0 +1,45 @@
+package pflag
+
+// -- multi Value
+type multiValue []Value
+
+// NewMultiValue returns a Value that sets each of values in turn, so that
+// a flag registered with Var can update several variables, such as a
+// legacy and a current one. Its String is that of the first value. Set
+// stops at the first error, leaving the values before it already set.
+func NewMultiValue(values ...Value) Value {
+	return multiValue(values)
+}
+
+func (m multiValue) Set(s string) error {
+	for _, v := range m {
+		if err := v.Set(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m multiValue) String() string {
+	if len(m) == 0 {
+		return ""
+	}
+	return m[0].String()
+}
+
+func (m multiValue) Get() interface{} {
+	if len(m) == 0 {
+		return nil
+	}
+	if g, ok := m[0].(Getter); ok {
+		return g.Get()
+	}
+	return m[0].String()
+}
+
+func (m multiValue) Type() string {
+	if len(m) == 0 {
+		return "value"
+	}
+	return valueType(m[0])
+}
@@ -0,0 +1,41 @@
+package pflag
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestMultiValue(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	var legacy, current int
+	f.Var(NewMultiValue(newIntValue(3, &legacy), newIntValue(3, &current)), "workers", "workers")
+	if err := f.Parse([]string{"--workers=8"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if legacy != 8 || current != 8 {
+		t.Fatalf("expected both targets to be 8 but got %d and %d", legacy, current)
+	}
+	if s := f.Lookup("workers").Value.String(); s != "8" {
+		t.Fatalf("expected %q but got %q", "8", s)
+	}
+	if f.Lookup("workers").DefValue != "3" {
+		t.Fatalf("expected default %q but got %q", "3", f.Lookup("workers").DefValue)
+	}
+}
+
+func TestMultiValueError(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	var name string
+	var count int
+	f.Var(NewMultiValue(newStringValue("", &name), newIntValue(0, &count)), "value", "value")
+	if err := f.Parse([]string{"--value=abc"}); err == nil {
+		t.Fatal("expected an error from the int target")
+	}
+	if err := f.Parse([]string{"--value=12"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if name != "12" || count != 12 {
+		t.Fatalf("expected both targets to be 12 but got %q and %d", name, count)
+	}
+}
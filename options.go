@@ -0,0 +1,39 @@
+package pflag
+
+import "io"
+
+// An Option configures a FlagSet created by NewFlagSetWith.
+type Option func(*FlagSet)
+
+// NewFlagSetWith is like NewFlagSet, but applies opts, in order, to the
+// new flag set before returning it.
+func NewFlagSetWith(name string, errorHandling ErrorHandling, opts ...Option) *FlagSet {
+	f := NewFlagSet(name, errorHandling)
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// WithOutput sets the destination for usage and error messages, as
+// SetOutput does.
+func WithOutput(output io.Writer) Option {
+	return func(f *FlagSet) { f.SetOutput(output) }
+}
+
+// WithInterspersed controls whether flags may follow positional arguments,
+// as SetInterspersed does.
+func WithInterspersed(interspersed bool) Option {
+	return func(f *FlagSet) { f.SetInterspersed(interspersed) }
+}
+
+// WithSortFlags sets the SortFlags field.
+func WithSortFlags(sortFlags bool) Option {
+	return func(f *FlagSet) { f.SortFlags = sortFlags }
+}
+
+// WithNormalizeFunc installs a flag name normalization function, as
+// SetNormalizeFunc does.
+func WithNormalizeFunc(n func(f *FlagSet, name string) NormalizedName) Option {
+	return func(f *FlagSet) { f.SetNormalizeFunc(n) }
+}
@@ -0,0 +1,50 @@
+package pflag
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewFlagSetWith(t *testing.T) {
+	var buf bytes.Buffer
+	f := NewFlagSetWith("test", ContinueOnError,
+		WithOutput(&buf),
+		WithInterspersed(false),
+		WithSortFlags(false),
+		WithNormalizeFunc(func(f *FlagSet, name string) NormalizedName {
+			return NormalizedName(strings.Replace(name, "_", "-", -1))
+		}),
+	)
+	verbose := f.Bool("verbose", false, "")
+	f.Int("max_count", 0, "")
+	f.String("addr", "", "")
+
+	if err := f.Parse([]string{"--verbose", "arg", "--addr=x"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !*verbose || !reflect.DeepEqual(f.Args(), []string{"arg", "--addr=x"}) {
+		t.Errorf("expected parsing to stop at the first positional; got verbose=%v args=%v", *verbose, f.Args())
+	}
+	if f.Lookup("max-count") == nil {
+		t.Error("expected the normalize function to be installed")
+	}
+	var names []string
+	f.VisitAll(func(flag *Flag) { names = append(names, flag.Name) })
+	if want := []string{"verbose", "max-count", "addr"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expected flags in definition order %v; got %v", want, names)
+	}
+	f.PrintDefaults()
+	if !strings.Contains(buf.String(), "--max-count") {
+		t.Errorf("expected usage in the configured output; got %q", buf.String())
+	}
+}
+
+func TestNewFlagSetWithDefaults(t *testing.T) {
+	f := NewFlagSetWith("test", ContinueOnError)
+	g := NewFlagSet("test", ContinueOnError)
+	if f.SortFlags != g.SortFlags || f.interspersed != g.interspersed || f.out() != g.out() {
+		t.Error("expected no options to give the same set as NewFlagSet")
+	}
+}
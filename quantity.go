@@ -0,0 +1,146 @@
+package pflag
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// -- quantity Value
+type quantityValue struct {
+	value  *float64
+	units  map[string]float64
+	number float64 // the number as given, before applying the unit
+	unit   string  // the unit as given; empty until the value is set
+}
+
+func newQuantityValue(val float64, units map[string]float64, p *float64) *quantityValue {
+	*p = val
+	return &quantityValue{value: p, units: units}
+}
+
+// Set parses a number followed by one of the allowed units, such as
+// "100Mbps", and stores the number multiplied by the unit's multiplier.
+func (q *quantityValue) Set(s string) error {
+	s = strings.TrimSpace(s)
+	unit := ""
+	for u := range q.units {
+		if strings.HasSuffix(s, u) && len(u) > len(unit) {
+			unit = u
+		}
+	}
+	if unit == "" {
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			return fmt.Errorf("missing unit in %q, expected one of %s", s, strings.Join(q.unitNames(), ", "))
+		}
+		return fmt.Errorf("unknown unit in %q, expected one of %s", s, strings.Join(q.unitNames(), ", "))
+	}
+	number, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, unit)), 64)
+	if err != nil {
+		return fmt.Errorf("invalid number in %q", s)
+	}
+	*q.value = number * q.units[unit]
+	q.number, q.unit = number, unit
+	return nil
+}
+
+func (q *quantityValue) String() string {
+	if q.unit != "" && *q.value == q.number*q.units[q.unit] {
+		return strconv.FormatFloat(q.number, 'g', -1, 64) + q.unit
+	}
+	// Render in the base unit, if there is one.
+	for _, u := range q.unitNames() {
+		if q.units[u] == 1 {
+			return strconv.FormatFloat(*q.value, 'g', -1, 64) + u
+		}
+	}
+	return strconv.FormatFloat(*q.value, 'g', -1, 64)
+}
+
+func (q *quantityValue) Type() string { return "quantity" }
+
+func (q *quantityValue) clone() Value {
+	v, c := *q.value, *q
+	c.value = &v
+	return &c
+}
+
+func (q *quantityValue) save() func() {
+	v, number, unit := *q.value, q.number, q.unit
+	return func() { *q.value, q.number, q.unit = v, number, unit }
+}
+
+func (q *quantityValue) Get() interface{} { return *q.value }
+
+// reset is like Set, but also accepts a bare number, which String prints
+// when there is no unit with a multiplier of 1.
+func (q *quantityValue) reset(s string) error {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return q.Set(s)
+	}
+	*q.value, q.number, q.unit = v, 0, ""
+	return nil
+}
+
+func (q *quantityValue) unitNames() []string {
+	names := make([]string, 0, len(q.units))
+	for u := range q.units {
+		names = append(names, u)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// QuantityVar defines a quantity flag with specified name, default value, units, and usage string.
+// The flag accepts a number followed by a unit, such as "100Mbps", where the unit is required
+// and must be a key of units; the value stored is the number times the unit's multiplier.
+// The argument p points to a float64 variable in which to store the value of the flag.
+func (f *FlagSet) QuantityVar(p *float64, name string, value float64, units map[string]float64, usage string) {
+	f.VarP(newQuantityValue(value, units, p), name, "", usage)
+}
+
+// Like QuantityVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) QuantityVarP(p *float64, name, shorthand string, value float64, units map[string]float64, usage string) {
+	f.VarP(newQuantityValue(value, units, p), name, shorthand, usage)
+}
+
+// QuantityVar defines a quantity flag with specified name, default value, units, and usage string.
+// The flag accepts a number followed by a unit, such as "100Mbps", where the unit is required
+// and must be a key of units; the value stored is the number times the unit's multiplier.
+// The argument p points to a float64 variable in which to store the value of the flag.
+func QuantityVar(p *float64, name string, value float64, units map[string]float64, usage string) {
+	CommandLine.VarP(newQuantityValue(value, units, p), name, "", usage)
+}
+
+// Like QuantityVar, but accepts a shorthand letter that can be used after a single dash.
+func QuantityVarP(p *float64, name, shorthand string, value float64, units map[string]float64, usage string) {
+	CommandLine.VarP(newQuantityValue(value, units, p), name, shorthand, usage)
+}
+
+// Quantity defines a quantity flag with specified name, default value, units, and usage string.
+// The return value is the address of a float64 variable that stores the value of the flag.
+func (f *FlagSet) Quantity(name string, value float64, units map[string]float64, usage string) *float64 {
+	p := new(float64)
+	f.QuantityVarP(p, name, "", value, units, usage)
+	return p
+}
+
+// Like Quantity, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) QuantityP(name, shorthand string, value float64, units map[string]float64, usage string) *float64 {
+	p := new(float64)
+	f.QuantityVarP(p, name, shorthand, value, units, usage)
+	return p
+}
+
+// Quantity defines a quantity flag with specified name, default value, units, and usage string.
+// The return value is the address of a float64 variable that stores the value of the flag.
+func Quantity(name string, value float64, units map[string]float64, usage string) *float64 {
+	return CommandLine.QuantityP(name, "", value, units, usage)
+}
+
+// Like Quantity, but accepts a shorthand letter that can be used after a single dash.
+func QuantityP(name, shorthand string, value float64, units map[string]float64, usage string) *float64 {
+	return CommandLine.QuantityP(name, shorthand, value, units, usage)
+}
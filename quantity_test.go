@@ -0,0 +1,59 @@
+package pflag
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+var testBandwidthUnits = map[string]float64{
+	"bps":  1,
+	"Kbps": 1e3,
+	"Mbps": 1e6,
+	"Gbps": 1e9,
+}
+
+func setUpQuantityFlagSet(bw *float64) *FlagSet {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	f.QuantityVar(bw, "bandwidth", 1e6, testBandwidthUnits, "bandwidth limit")
+	return f
+}
+
+func TestQuantityValid(t *testing.T) {
+	var bw float64
+	f := setUpQuantityFlagSet(&bw)
+	if s := f.Lookup("bandwidth").DefValue; s != "1e+06bps" {
+		t.Fatalf("expected default %q but got %q", "1e+06bps", s)
+	}
+	if err := f.Parse([]string{"--bandwidth=100Mbps"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if bw != 100e6 {
+		t.Fatal("expected 1e8 but got", bw)
+	}
+	if s := f.Lookup("bandwidth").Value.String(); s != "100Mbps" {
+		t.Fatalf("expected %q but got %q", "100Mbps", s)
+	}
+}
+
+func TestQuantityMissingUnit(t *testing.T) {
+	var bw float64
+	f := setUpQuantityFlagSet(&bw)
+	err := f.Parse([]string{"--bandwidth=100"})
+	if err == nil || !strings.Contains(err.Error(), "missing unit") {
+		t.Fatal("expected a missing unit error; got", err)
+	}
+	if bw != 1e6 {
+		t.Fatal("expected the default to be unchanged but got", bw)
+	}
+}
+
+func TestQuantityUnknownUnit(t *testing.T) {
+	var bw float64
+	f := setUpQuantityFlagSet(&bw)
+	err := f.Parse([]string{"--bandwidth=100MBps"})
+	if err == nil || !strings.Contains(err.Error(), "unknown unit") {
+		t.Fatal("expected an unknown unit error; got", err)
+	}
+}
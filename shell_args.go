@@ -0,0 +1,86 @@
+package pflag
+
+import (
+	"errors"
+	"strings"
+)
+
+// SplitArgs splits s into arguments the way a POSIX shell would, without
+// expanding variables or globs. Arguments are separated by unquoted
+// whitespace. Inside single quotes every character is literal. Inside
+// double quotes a backslash escapes only ", \, $ and `, and is otherwise
+// kept. Elsewhere a backslash makes the next character literal. Outside
+// single quotes, a backslash before a newline removes both. An
+// unterminated quote or a trailing backslash is an error.
+func SplitArgs(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		case c == '\\':
+			if i+1 == len(s) {
+				return nil, errors.New("trailing backslash")
+			}
+			i++
+			if s[i] != '\n' {
+				arg.WriteByte(s[i])
+				inArg = true
+			}
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated single quote")
+			}
+			arg.WriteString(s[i+1 : i+1+end])
+			i += 1 + end
+			inArg = true
+		case c == '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) && s[i+1] == '\n' {
+					i++
+					continue
+				}
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`", s[i+1]) >= 0 {
+					i++
+				}
+				arg.WriteByte(s[i])
+			}
+			if i == len(s) {
+				return nil, errors.New("unterminated double quote")
+			}
+			inArg = true
+		default:
+			arg.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// ParseString splits s into arguments with SplitArgs and parses them as
+// Parse does, so that tests and interactive programs can supply a command
+// line as one string. An error splitting s is returned without parsing.
+func (f *FlagSet) ParseString(s string) error {
+	args, err := SplitArgs(s)
+	if err != nil {
+		return err
+	}
+	return f.Parse(args)
+}
+
+// ParseString splits s into arguments with SplitArgs and parses them as the command-line flags.
+func ParseString(s string) error {
+	return CommandLine.ParseString(s)
+}
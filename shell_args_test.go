@@ -0,0 +1,66 @@
+package pflag
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{`a "b c" d`, []string{"a", "b c", "d"}},
+		{``, nil},
+		{`  a   b  `, []string{"a", "b"}},
+		{`'it''s' "x"y`, []string{"its", "xy"}},
+		{`'a "b" \c'`, []string{`a "b" \c`}},
+		{`"a \"b\" \c \\ \$"`, []string{`a "b" \c \ $`}},
+		{`a\ b \"c\"`, []string{"a b", `"c"`}},
+		{"a\\\nb", []string{"ab"}},
+		{"\"a\\\nb\" 'c\\\nd'", []string{"ab", "c\\\nd"}},
+		{`"" ''`, []string{"", ""}},
+		{`--name="hello world" -v`, []string{"--name=hello world", "-v"}},
+	}
+	for _, test := range tests {
+		got, err := SplitArgs(test.in)
+		if err != nil {
+			t.Errorf("%q: expected no error; got %v", test.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: expected %q; got %q", test.in, test.want, got)
+		}
+	}
+}
+
+func TestSplitArgsInvalid(t *testing.T) {
+	for in, want := range map[string]string{
+		`a "b c`: "unterminated double quote",
+		`a 'b c`: "unterminated single quote",
+		`a "b\"`: "unterminated double quote",
+		`a b\`:   "trailing backslash",
+	} {
+		if _, err := SplitArgs(in); err == nil || err.Error() != want {
+			t.Errorf("%q: expected %q; got %v", in, want, err)
+		}
+	}
+}
+
+func TestParseString(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	name := f.String("name", "", "")
+	verbose := f.BoolP("verbose", "v", false, "")
+	if err := f.ParseString(`--name "hello world" -v 'arg one' two`); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if *name != "hello world" || !*verbose {
+		t.Errorf("got name=%q verbose=%v", *name, *verbose)
+	}
+	if want := []string{"arg one", "two"}; !reflect.DeepEqual(f.Args(), want) {
+		t.Errorf("expected args %q; got %q", want, f.Args())
+	}
+	if err := f.ParseString(`--name "oops`); err == nil {
+		t.Error("expected an error for an unterminated quote")
+	}
+}
@@ -0,0 +1,77 @@
+package pflag
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestSizedInts(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	i8 := f.Int8("int8", 0, "")
+	i16 := f.Int16P("int16", "s", 0, "")
+	i32 := f.Int32("int32", 0, "")
+	u8 := f.Uint8("uint8", 0, "")
+	u16 := f.Uint16("uint16", 0, "")
+	u32 := f.Uint32("uint32", 0, "")
+	args := []string{
+		"--int8=-128", "-s", "32767", "--int32=-2147483648",
+		"--uint8=255", "--uint16=0xffff", "--uint32=4294967295",
+	}
+	if err := f.Parse(args); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if *i8 != -128 || *i16 != 32767 || *i32 != -2147483648 {
+		t.Errorf("expected signed boundaries, got %d %d %d", *i8, *i16, *i32)
+	}
+	if *u8 != 255 || *u16 != 65535 || *u32 != 4294967295 {
+		t.Errorf("expected unsigned boundaries, got %d %d %d", *u8, *u16, *u32)
+	}
+	if err := f.Parse([]string{"--int8=127"}); err != nil || *i8 != 127 {
+		t.Errorf("expected 127, got %d, %v", *i8, err)
+	}
+
+	if v, err := f.GetInt8("int8"); err != nil || v != 127 {
+		t.Errorf("expected GetInt8 to return 127, got %d, %v", v, err)
+	}
+	if v, err := f.GetInt16("int16"); err != nil || v != 32767 {
+		t.Errorf("expected GetInt16 to return 32767, got %d, %v", v, err)
+	}
+	if v, err := f.GetInt32("int32"); err != nil || v != -2147483648 {
+		t.Errorf("expected GetInt32 to return -2147483648, got %d, %v", v, err)
+	}
+	if v, err := f.GetUint8("uint8"); err != nil || v != 255 {
+		t.Errorf("expected GetUint8 to return 255, got %d, %v", v, err)
+	}
+	if v, err := f.GetUint16("uint16"); err != nil || v != 65535 {
+		t.Errorf("expected GetUint16 to return 65535, got %d, %v", v, err)
+	}
+	if v, err := f.GetUint32("uint32"); err != nil || v != 4294967295 {
+		t.Errorf("expected GetUint32 to return 4294967295, got %d, %v", v, err)
+	}
+	if _, err := f.GetInt8("uint8"); err == nil {
+		t.Error("expected an error getting a uint8 flag as int8")
+	}
+}
+
+func TestSizedIntOverflow(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	i8 := f.Int8("int8", 1, "")
+	f.Int16("int16", 0, "")
+	f.Int32("int32", 0, "")
+	u8 := f.Uint8("uint8", 1, "")
+	f.Uint16("uint16", 0, "")
+	f.Uint32("uint32", 0, "")
+	for _, arg := range []string{
+		"--int8=128", "--int8=-129", "--int16=32768", "--int32=2147483648",
+		"--uint8=256", "--uint8=-1", "--uint16=65536", "--uint32=4294967296",
+	} {
+		if err := f.Parse([]string{arg}); err == nil {
+			t.Errorf("%s: expected an overflow error", arg)
+		}
+	}
+	if *i8 != 1 || *u8 != 1 {
+		t.Errorf("expected values to be unchanged, got %d and %d", *i8, *u8)
+	}
+}
@@ -17,6 +17,20 @@ func (s *stringValue) Set(val string) error {
 
 func (s *stringValue) String() string { return fmt.Sprintf("%s", *s) }
 
+func (s *stringValue) Type() string { return "string" }
+
+func (s *stringValue) clone() Value {
+	v := *s
+	return &v
+}
+
+func (s *stringValue) save() func() {
+	v := *s
+	return func() { *s = v }
+}
+
+func (s *stringValue) Get() interface{} { return string(*s) }
+
 // StringVar defines a string flag with specified name, default value, and usage string.
 // The argument p points to a string variable in which to store the value of the flag.
 func (f *FlagSet) StringVar(p *string, name string, value string, usage string) {
@@ -64,3 +78,35 @@ func String(name string, value string, usage string) *string {
 func StringP(name, shorthand string, value string, usage string) *string {
 	return CommandLine.StringP(name, shorthand, value, usage)
 }
+
+// GetString returns the string value of the named flag.
+func (f *FlagSet) GetString(name string) (string, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return "", err
+	}
+	tv, ok := v.(*stringValue)
+	if !ok {
+		return "", fmt.Errorf("flag -%v is not a string flag", name)
+	}
+	return string(*tv), nil
+}
+
+// SetString sets the named string flag to value and marks it as set, as Set
+// does, without formatting and parsing the value.
+func (f *FlagSet) SetString(name string, value string) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	tv, ok := flag.Value.(*stringValue)
+	if !ok {
+		return fmt.Errorf("flag -%v is not a string flag", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	*tv = stringValue(value)
+	f.markSet(flag, SourceAPI)
+	return nil
+}
@@ -0,0 +1,114 @@
+package pflag
+
+import "strings"
+
+// -- []string Value, one element per use
+type stringArrayValue []string
+
+func newStringArrayValue(val []string, p *[]string) *stringArrayValue {
+	*p = val
+	return (*stringArrayValue)(p)
+}
+
+// Set appends val to the slice as a single element, without splitting it.
+func (s *stringArrayValue) Set(val string) error {
+	*s = append(*s, val)
+	return nil
+}
+
+func (s *stringArrayValue) String() string {
+	str, _ := writeAsCSV(*s)
+	return "[" + str + "]"
+}
+
+func (s *stringArrayValue) Type() string { return "stringArray" }
+
+func (s *stringArrayValue) clone() Value {
+	v := append(stringArrayValue(nil), *s...)
+	return &v
+}
+
+func (s *stringArrayValue) save() func() {
+	v := append(stringArrayValue(nil), *s...)
+	return func() { *s = append(stringArrayValue(nil), v...) }
+}
+
+func (s *stringArrayValue) Get() interface{} { return []string(*s) }
+
+// Append adds val to the slice as a single element.
+func (s *stringArrayValue) Append(val string) error {
+	*s = append(*s, val)
+	return nil
+}
+
+// Replace sets the slice to vals.
+func (s *stringArrayValue) Replace(vals []string) error {
+	*s = append(stringArrayValue(nil), vals...)
+	return nil
+}
+
+// GetSlice returns a copy of the elements.
+func (s *stringArrayValue) GetSlice() []string {
+	return append([]string(nil), *s...)
+}
+
+func (s *stringArrayValue) restore(val string) error {
+	v, err := readAsCSV(strings.TrimSuffix(strings.TrimPrefix(val, "["), "]"))
+	if err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// StringArrayVar defines a []string flag with specified name, default value, and usage string.
+// Each use of the flag appends its value to the slice as a single element, commas
+// and all, so "--arg a,b --arg c" yields ["a,b" "c"]. The first use replaces the default.
+// The argument p points to a []string variable in which to store the value of the flag.
+func (f *FlagSet) StringArrayVar(p *[]string, name string, value []string, usage string) {
+	f.VarP(newStringArrayValue(value, p), name, "", usage)
+}
+
+// Like StringArrayVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) StringArrayVarP(p *[]string, name, shorthand string, value []string, usage string) {
+	f.VarP(newStringArrayValue(value, p), name, shorthand, usage)
+}
+
+// StringArrayVar defines a []string flag with specified name, default value, and usage string.
+// Each use of the flag appends its value to the slice as a single element, commas
+// and all, so "--arg a,b --arg c" yields ["a,b" "c"]. The first use replaces the default.
+// The argument p points to a []string variable in which to store the value of the flag.
+func StringArrayVar(p *[]string, name string, value []string, usage string) {
+	CommandLine.VarP(newStringArrayValue(value, p), name, "", usage)
+}
+
+// Like StringArrayVar, but accepts a shorthand letter that can be used after a single dash.
+func StringArrayVarP(p *[]string, name, shorthand string, value []string, usage string) {
+	CommandLine.VarP(newStringArrayValue(value, p), name, shorthand, usage)
+}
+
+// StringArray defines a []string flag with specified name, default value, and usage string.
+// The return value is the address of a []string variable that stores the value of the flag.
+func (f *FlagSet) StringArray(name string, value []string, usage string) *[]string {
+	p := new([]string)
+	f.StringArrayVarP(p, name, "", value, usage)
+	return p
+}
+
+// Like StringArray, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) StringArrayP(name, shorthand string, value []string, usage string) *[]string {
+	p := new([]string)
+	f.StringArrayVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// StringArray defines a []string flag with specified name, default value, and usage string.
+// The return value is the address of a []string variable that stores the value of the flag.
+func StringArray(name string, value []string, usage string) *[]string {
+	return CommandLine.StringArrayP(name, "", value, usage)
+}
+
+// Like StringArray, but accepts a shorthand letter that can be used after a single dash.
+func StringArrayP(name, shorthand string, value []string, usage string) *[]string {
+	return CommandLine.StringArrayP(name, shorthand, value, usage)
+}
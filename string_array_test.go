@@ -0,0 +1,55 @@
+package pflag
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStringArray(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	args := f.StringArrayP("arg", "a", nil, "arguments")
+	if err := f.Parse([]string{"--arg", "a,b", "-a", "c", "--arg=d e"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	expected := []string{"a,b", "c", "d e"}
+	if !reflect.DeepEqual(*args, expected) {
+		t.Fatalf("expected %q but got %q", expected, *args)
+	}
+	if s := f.Lookup("arg").Value.String(); s != `["a,b",c,d e]` {
+		t.Fatalf("expected %q but got %q", `["a,b",c,d e]`, s)
+	}
+}
+
+func TestStringArrayRestore(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	args := f.StringArray("arg", []string{"x,y"}, "arguments")
+	snap := f.Snapshot()
+	if err := f.Parse([]string{"--arg=z"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if err := f.Restore(snap); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !reflect.DeepEqual(*args, []string{"x,y"}) {
+		t.Fatalf("expected %q but got %q", []string{"x,y"}, *args)
+	}
+}
+
+func TestStringArrayEmptyElement(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	args := f.StringArray("arg", nil, "arguments")
+	if err := f.Parse([]string{"--arg="}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if s := f.Lookup("arg").Value.String(); s != `[""]` {
+		t.Fatalf("expected %q but got %q", `[""]`, s)
+	}
+	snap := f.Snapshot()
+	*args = nil
+	if err := f.Restore(snap); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !reflect.DeepEqual(*args, []string{""}) {
+		t.Fatalf("expected %q but got %q", []string{""}, *args)
+	}
+}
@@ -0,0 +1,96 @@
+package pflag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// -- string Value restricted to a set of choices
+type stringEnumValue struct {
+	value   *string
+	allowed []string
+}
+
+func newStringEnumValue(allowed []string, val string, p *string) *stringEnumValue {
+	*p = val
+	return &stringEnumValue{value: p, allowed: allowed}
+}
+
+// Set accepts s only if it is exactly one of the allowed values.
+func (e *stringEnumValue) Set(s string) error {
+	for _, a := range e.allowed {
+		if s == a {
+			*e.value = s
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(e.allowed, ", "))
+}
+
+func (e *stringEnumValue) String() string { return *e.value }
+
+func (e *stringEnumValue) Type() string { return "string" }
+
+func (e *stringEnumValue) clone() Value {
+	v := *e.value
+	return &stringEnumValue{value: &v, allowed: e.allowed}
+}
+
+func (e *stringEnumValue) save() func() {
+	v := *e.value
+	return func() { *e.value = v }
+}
+
+func (e *stringEnumValue) Get() interface{} { return *e.value }
+
+// StringEnumVar defines a string flag with specified name, allowed values, default value,
+// and usage string. Setting the flag to anything not in allowed, which is case-sensitive,
+// is an error. The argument p points to a string variable in which to store the value of the flag.
+func (f *FlagSet) StringEnumVar(p *string, name string, allowed []string, value string, usage string) {
+	f.VarP(newStringEnumValue(allowed, value, p), name, "", usage)
+}
+
+// Like StringEnumVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) StringEnumVarP(p *string, name, shorthand string, allowed []string, value string, usage string) {
+	f.VarP(newStringEnumValue(allowed, value, p), name, shorthand, usage)
+}
+
+// StringEnumVar defines a string flag with specified name, allowed values, default value,
+// and usage string. Setting the flag to anything not in allowed, which is case-sensitive,
+// is an error. The argument p points to a string variable in which to store the value of the flag.
+func StringEnumVar(p *string, name string, allowed []string, value string, usage string) {
+	CommandLine.VarP(newStringEnumValue(allowed, value, p), name, "", usage)
+}
+
+// Like StringEnumVar, but accepts a shorthand letter that can be used after a single dash.
+func StringEnumVarP(p *string, name, shorthand string, allowed []string, value string, usage string) {
+	CommandLine.VarP(newStringEnumValue(allowed, value, p), name, shorthand, usage)
+}
+
+// StringEnum defines a string flag with specified name, allowed values, default value,
+// and usage string. The return value is the address of a string variable that stores
+// the value of the flag.
+func (f *FlagSet) StringEnum(name string, allowed []string, value string, usage string) *string {
+	p := new(string)
+	f.StringEnumVarP(p, name, "", allowed, value, usage)
+	return p
+}
+
+// Like StringEnum, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) StringEnumP(name, shorthand string, allowed []string, value string, usage string) *string {
+	p := new(string)
+	f.StringEnumVarP(p, name, shorthand, allowed, value, usage)
+	return p
+}
+
+// StringEnum defines a string flag with specified name, allowed values, default value,
+// and usage string. The return value is the address of a string variable that stores
+// the value of the flag.
+func StringEnum(name string, allowed []string, value string, usage string) *string {
+	return CommandLine.StringEnumP(name, "", allowed, value, usage)
+}
+
+// Like StringEnum, but accepts a shorthand letter that can be used after a single dash.
+func StringEnumP(name, shorthand string, allowed []string, value string, usage string) *string {
+	return CommandLine.StringEnumP(name, shorthand, allowed, value, usage)
+}
@@ -0,0 +1,39 @@
+package pflag
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestStringEnum(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	format := f.StringEnumP("format", "f", []string{"json", "yaml", "text"}, "text", "output format")
+	if err := f.Parse([]string{"--format=json"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if *format != "json" {
+		t.Fatalf("expected %q but got %q", "json", *format)
+	}
+
+	err := f.Parse([]string{"-f", "xml"})
+	if err == nil || !strings.Contains(err.Error(), "json, yaml, text") {
+		t.Fatal("expected an error listing the choices; got", err)
+	}
+	if err := f.Parse([]string{"--format=JSON"}); err == nil {
+		t.Fatal("expected an error for a value differing only in case")
+	}
+	if *format != "json" {
+		t.Fatalf("expected the value to be unchanged but got %q", *format)
+	}
+}
+
+func TestStringEnumUsage(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.StringEnum("format", []string{"json", "yaml"}, "json", "output format")
+	want := "  --format string   output format (one of json, yaml) (default \"json\")\n"
+	if got := f.FlagUsages(); got != want {
+		t.Fatalf("expected %q but got %q", want, got)
+	}
+}
@@ -0,0 +1,175 @@
+package pflag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// -- map[string]string Value
+type stringMapValue struct {
+	value *map[string]string
+}
+
+func newStringMapValue(val map[string]string, p *map[string]string) *stringMapValue {
+	// Copy the default so that Set does not write into the caller's map.
+	*p = nil
+	if val != nil {
+		*p = make(map[string]string, len(val))
+		for k, v := range val {
+			(*p)[k] = v
+		}
+	}
+	return &stringMapValue{value: p}
+}
+
+// Set adds the comma-separated key=value pairs in s to the map, replacing
+// the values of keys that are already present.
+func (m *stringMapValue) Set(s string) error {
+	pairs := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return fmt.Errorf("expected key=value, got %q", pair)
+		}
+		pairs[kv[0]] = kv[1]
+	}
+	if *m.value == nil {
+		*m.value = make(map[string]string)
+	}
+	for k, v := range pairs {
+		(*m.value)[k] = v
+	}
+	return nil
+}
+
+func (m *stringMapValue) String() string {
+	pairs := make([]string, 0, len(*m.value))
+	for k, v := range *m.value {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return "[" + strings.Join(pairs, ",") + "]"
+}
+
+func (m *stringMapValue) Type() string { return "stringMap" }
+
+func (m *stringMapValue) clone() Value {
+	var v map[string]string
+	if *m.value != nil {
+		v = make(map[string]string, len(*m.value))
+		for key, value := range *m.value {
+			v[key] = value
+		}
+	}
+	return &stringMapValue{value: &v}
+}
+
+func (m *stringMapValue) save() func() {
+	v := m.clone().(*stringMapValue)
+	return func() { *m.value = *v.clone().(*stringMapValue).value }
+}
+
+func (m *stringMapValue) Get() interface{} { return *m.value }
+
+func (m *stringMapValue) restore(s string) error {
+	v := make(map[string]string)
+	if s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"); s != "" {
+		if err := (&stringMapValue{value: &v}).Set(s); err != nil {
+			return err
+		}
+	}
+	*m.value = v
+	return nil
+}
+
+// StringMapVar defines a map[string]string flag with specified name, default value, and usage string.
+// Each use of the flag adds one or more comma-separated key=value pairs to the map.
+// The argument p points to a map[string]string variable in which to store the value of the flag.
+func (f *FlagSet) StringMapVar(p *map[string]string, name string, value map[string]string, usage string) {
+	f.VarP(newStringMapValue(value, p), name, "", usage)
+}
+
+// Like StringMapVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) StringMapVarP(p *map[string]string, name, shorthand string, value map[string]string, usage string) {
+	f.VarP(newStringMapValue(value, p), name, shorthand, usage)
+}
+
+// StringMapVar defines a map[string]string flag with specified name, default value, and usage string.
+// Each use of the flag adds one or more comma-separated key=value pairs to the map.
+// The argument p points to a map[string]string variable in which to store the value of the flag.
+func StringMapVar(p *map[string]string, name string, value map[string]string, usage string) {
+	CommandLine.VarP(newStringMapValue(value, p), name, "", usage)
+}
+
+// Like StringMapVar, but accepts a shorthand letter that can be used after a single dash.
+func StringMapVarP(p *map[string]string, name, shorthand string, value map[string]string, usage string) {
+	CommandLine.VarP(newStringMapValue(value, p), name, shorthand, usage)
+}
+
+// StringMap defines a map[string]string flag with specified name, default value, and usage string.
+// The return value is the address of a map[string]string variable that stores the value of the flag.
+func (f *FlagSet) StringMap(name string, value map[string]string, usage string) *map[string]string {
+	p := new(map[string]string)
+	f.StringMapVarP(p, name, "", value, usage)
+	return p
+}
+
+// Like StringMap, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) StringMapP(name, shorthand string, value map[string]string, usage string) *map[string]string {
+	p := new(map[string]string)
+	f.StringMapVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// StringMap defines a map[string]string flag with specified name, default value, and usage string.
+// The return value is the address of a map[string]string variable that stores the value of the flag.
+func StringMap(name string, value map[string]string, usage string) *map[string]string {
+	return CommandLine.StringMapP(name, "", value, usage)
+}
+
+// Like StringMap, but accepts a shorthand letter that can be used after a single dash.
+func StringMapP(name, shorthand string, value map[string]string, usage string) *map[string]string {
+	return CommandLine.StringMapP(name, shorthand, value, usage)
+}
+
+// MarkGreedyMap makes the named flag consume, after its own value, every
+// following argument of the form key=value, so that "--env A=1 B=2 file"
+// sets both A and B and leaves "file" as a positional argument. Consumption
+// stops at the first argument that begins with a dash or has no "=".
+// It is intended for map flags such as StringMap.
+func (f *FlagSet) MarkGreedyMap(name string) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	if bv, ok := flag.Value.(boolFlag); ok && bv.IsBoolFlag() {
+		return fmt.Errorf("flag -%v is a boolean flag", name)
+	}
+	if f.greedyMaps == nil {
+		f.greedyMaps = make(map[*Flag]bool)
+	}
+	f.greedyMaps[flag] = true
+	return nil
+}
+
+// MarkGreedyMap makes the named command-line flag consume following key=value arguments.
+func MarkGreedyMap(name string) error {
+	return CommandLine.MarkGreedyMap(name)
+}
+
+// consumeGreedy feeds leading key=value arguments to a greedy map flag and
+// returns the arguments that remain.
+func (f *FlagSet) consumeGreedy(flag *Flag, args []string) ([]string, error) {
+	for len(args) > 0 {
+		s := args[0]
+		if strings.HasPrefix(s, "-") || !strings.Contains(s, "=") {
+			break
+		}
+		if err := f.setFlag(flag, s, s); err != nil {
+			return args, err
+		}
+		args = args[1:]
+	}
+	return args, nil
+}
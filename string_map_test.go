@@ -0,0 +1,66 @@
+package pflag
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStringMap(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	env := f.StringMapP("env", "e", nil, "environment")
+	if err := f.Parse([]string{"--env=A=1,B=2", "-e", "C=3=x"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	want := map[string]string{"A": "1", "B": "2", "C": "3=x"}
+	if !reflect.DeepEqual(*env, want) {
+		t.Fatalf("expected %v but got %v", want, *env)
+	}
+	if s := f.Lookup("env").Value.String(); s != "[A=1,B=2,C=3=x]" {
+		t.Fatalf("expected %q but got %q", "[A=1,B=2,C=3=x]", s)
+	}
+	if err := f.Set("env", "novalue"); err == nil {
+		t.Fatal("expected an error for a pair without =")
+	}
+}
+
+func TestStringMapDefaultNotShared(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	def := map[string]string{"k": "v"}
+	labels := f.StringMap("label", def, "")
+	if err := f.Parse([]string{"--label=a=1", "--label=k=w"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if want := map[string]string{"k": "w", "a": "1"}; !reflect.DeepEqual(*labels, want) {
+		t.Errorf("expected %v but got %v", want, *labels)
+	}
+	if want := map[string]string{"k": "v"}; !reflect.DeepEqual(def, want) {
+		t.Errorf("expected the default map to be left as %v, got %v", want, def)
+	}
+}
+
+func TestGreedyMap(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	env := f.StringMapP("env", "e", nil, "environment")
+	verbose := f.BoolP("verbose", "v", false, "verbose output")
+	if err := f.MarkGreedyMap("env"); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if err := f.Parse([]string{"--env", "A=1", "B=2", "C=3", "file", "D=4"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	want := map[string]string{"A": "1", "B": "2", "C": "3"}
+	if !reflect.DeepEqual(*env, want) {
+		t.Fatalf("expected %v but got %v", want, *env)
+	}
+	if args := f.Args(); !reflect.DeepEqual(args, []string{"file", "D=4"}) {
+		t.Fatalf("expected args [file D=4] but got %v", args)
+	}
+
+	*env = nil
+	if err := f.Parse([]string{"-e", "A=1", "B=2", "-v"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !reflect.DeepEqual(*env, map[string]string{"A": "1", "B": "2"}) || !*verbose {
+		t.Fatalf("expected greedy consumption to stop at a flag, got %v verbose=%v", *env, *verbose)
+	}
+}
@@ -0,0 +1,178 @@
+package pflag
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// -- []string Value
+type stringSliceValue []string
+
+func newStringSliceValue(val []string, p *[]string) *stringSliceValue {
+	*p = val
+	return (*stringSliceValue)(p)
+}
+
+// readAsCSV splits a comma-separated value, honouring double quotes so
+// that a quoted element may itself contain commas. A newline outside
+// quotes separates elements like a comma does. A value whose quotes do not
+// delimit elements, such as one holding a lone ", is split on commas and
+// newlines alone, keeping its quotes.
+func readAsCSV(val string) ([]string, error) {
+	if val == "" {
+		return []string{}, nil
+	}
+	r := csv.NewReader(strings.NewReader(val))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if perr, ok := err.(*csv.ParseError); ok && (perr.Err == csv.ErrQuote || perr.Err == csv.ErrBareQuote) {
+		return strings.Split(strings.Replace(val, "\n", ",", -1), ","), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	for _, record := range records {
+		fields = append(fields, record...)
+	}
+	return fields, nil
+}
+
+func writeAsCSV(vals []string) (string, error) {
+	if len(vals) == 1 && vals[0] == "" {
+		// encoding/csv writes a lone empty field as an empty line, which
+		// would read back as no elements at all.
+		return `""`, nil
+	}
+	b := &bytes.Buffer{}
+	w := csv.NewWriter(b)
+	if err := w.Write(vals); err != nil {
+		return "", err
+	}
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// Set appends the comma-separated elements of val to the slice.
+func (s *stringSliceValue) Set(val string) error {
+	v, err := readAsCSV(val)
+	if err != nil {
+		return err
+	}
+	*s = append(*s, v...)
+	return nil
+}
+
+func (s *stringSliceValue) String() string {
+	str, _ := writeAsCSV(*s)
+	return "[" + str + "]"
+}
+
+func (s *stringSliceValue) Type() string { return "stringSlice" }
+
+func (s *stringSliceValue) clone() Value {
+	v := append(stringSliceValue(nil), *s...)
+	return &v
+}
+
+func (s *stringSliceValue) save() func() {
+	v := append(stringSliceValue(nil), *s...)
+	return func() { *s = append(stringSliceValue(nil), v...) }
+}
+
+func (s *stringSliceValue) Get() interface{} { return []string(*s) }
+
+// Append adds val to the slice as a single element.
+func (s *stringSliceValue) Append(val string) error {
+	*s = append(*s, val)
+	return nil
+}
+
+// Replace sets the slice to vals.
+func (s *stringSliceValue) Replace(vals []string) error {
+	*s = append(stringSliceValue(nil), vals...)
+	return nil
+}
+
+// GetSlice returns a copy of the elements.
+func (s *stringSliceValue) GetSlice() []string {
+	return append([]string(nil), *s...)
+}
+
+func (s *stringSliceValue) restore(val string) error {
+	var v stringSliceValue
+	if err := v.Set(strings.TrimSuffix(strings.TrimPrefix(val, "["), "]")); err != nil {
+		return err
+	}
+	*s = v
+	return nil
+}
+
+// StringSliceVar defines a []string flag with specified name, default value, and usage string.
+// Each use of the flag appends its comma-separated elements to the slice, so
+// "--tag a,b --tag c" yields [a b c], replacing the default. Elements may be
+// double-quoted to include commas.
+// The argument p points to a []string variable in which to store the value of the flag.
+func (f *FlagSet) StringSliceVar(p *[]string, name string, value []string, usage string) {
+	f.VarP(newStringSliceValue(value, p), name, "", usage)
+}
+
+// Like StringSliceVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) StringSliceVarP(p *[]string, name, shorthand string, value []string, usage string) {
+	f.VarP(newStringSliceValue(value, p), name, shorthand, usage)
+}
+
+// StringSliceVar defines a []string flag with specified name, default value, and usage string.
+// Each use of the flag appends its comma-separated elements to the slice, so
+// "--tag a,b --tag c" yields [a b c], replacing the default. Elements may be
+// double-quoted to include commas.
+// The argument p points to a []string variable in which to store the value of the flag.
+func StringSliceVar(p *[]string, name string, value []string, usage string) {
+	CommandLine.VarP(newStringSliceValue(value, p), name, "", usage)
+}
+
+// Like StringSliceVar, but accepts a shorthand letter that can be used after a single dash.
+func StringSliceVarP(p *[]string, name, shorthand string, value []string, usage string) {
+	CommandLine.VarP(newStringSliceValue(value, p), name, shorthand, usage)
+}
+
+// StringSlice defines a []string flag with specified name, default value, and usage string.
+// The return value is the address of a []string variable that stores the value of the flag.
+func (f *FlagSet) StringSlice(name string, value []string, usage string) *[]string {
+	p := new([]string)
+	f.StringSliceVarP(p, name, "", value, usage)
+	return p
+}
+
+// Like StringSlice, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) StringSliceP(name, shorthand string, value []string, usage string) *[]string {
+	p := new([]string)
+	f.StringSliceVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// StringSlice defines a []string flag with specified name, default value, and usage string.
+// The return value is the address of a []string variable that stores the value of the flag.
+func StringSlice(name string, value []string, usage string) *[]string {
+	return CommandLine.StringSliceP(name, "", value, usage)
+}
+
+// Like StringSlice, but accepts a shorthand letter that can be used after a single dash.
+func StringSliceP(name, shorthand string, value []string, usage string) *[]string {
+	return CommandLine.StringSliceP(name, shorthand, value, usage)
+}
+
+// GetStringSlice returns a copy of the []string value of the named flag.
+func (f *FlagSet) GetStringSlice(name string) ([]string, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return nil, err
+	}
+	sv, ok := v.(*stringSliceValue)
+	if !ok {
+		return nil, fmt.Errorf("flag -%v is not a string slice flag", name)
+	}
+	return append([]string(nil), *sv...), nil
+}
@@ -0,0 +1,160 @@
+package pflag
+
+import (
+	"reflect"
+	"testing"
+)
+
+func setUpStringSliceFlagSet(tags *[]string) *FlagSet {
+	f := NewFlagSet("test", ContinueOnError)
+	f.StringSliceVarP(tags, "tag", "t", nil, "tags")
+	return f
+}
+
+func TestStringSliceRepeated(t *testing.T) {
+	var tags []string
+	f := setUpStringSliceFlagSet(&tags)
+	if err := f.Parse([]string{"--tag", "a,b", "-t", "c", "--tag=d"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !reflect.DeepEqual(tags, []string{"a", "b", "c", "d"}) {
+		t.Fatal("expected [a b c d] but got", tags)
+	}
+	if s := f.Lookup("tag").Value.String(); s != "[a,b,c,d]" {
+		t.Fatalf("expected %q but got %q", "[a,b,c,d]", s)
+	}
+}
+
+func TestStringSliceEmbeddedCommas(t *testing.T) {
+	var tags []string
+	f := setUpStringSliceFlagSet(&tags)
+	if err := f.Parse([]string{`--tag="a,b",c`}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !reflect.DeepEqual(tags, []string{"a,b", "c"}) {
+		t.Fatalf("expected [a,b c] but got %q", tags)
+	}
+	if s := f.Lookup("tag").Value.String(); s != `["a,b",c]` {
+		t.Fatalf("expected %q but got %q", `["a,b",c]`, s)
+	}
+}
+
+func TestStringSliceQuotes(t *testing.T) {
+	var tags []string
+	f := setUpStringSliceFlagSet(&tags)
+	if err := f.Parse([]string{`--tag="`, `--tag=5",x`, `--tag=a"b`}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !reflect.DeepEqual(tags, []string{`"`, `5"`, "x", `a"b`}) {
+		t.Fatalf("expected stray quotes to be kept but got %q", tags)
+	}
+}
+
+func TestStringSliceNewlines(t *testing.T) {
+	var tags []string
+	f := setUpStringSliceFlagSet(&tags)
+	if err := f.Parse([]string{"--tag=a\nb,c", "--tag=\"d\ne\""}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !reflect.DeepEqual(tags, []string{"a", "b", "c", "d\ne"}) {
+		t.Fatalf("expected [a b c d\\ne] but got %q", tags)
+	}
+}
+
+func TestStringSliceEmpty(t *testing.T) {
+	var tags []string
+	f := setUpStringSliceFlagSet(&tags)
+	if err := f.Parse([]string{"--tag="}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if len(tags) != 0 {
+		t.Fatal("expected an empty slice but got", tags)
+	}
+	if s := f.Lookup("tag").Value.String(); s != "[]" {
+		t.Fatalf("expected %q but got %q", "[]", s)
+	}
+}
+
+func TestStringSliceReplacesDefault(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	tags := f.StringSlice("tag", []string{"default"}, "tags")
+	if err := f.Parse([]string{}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !reflect.DeepEqual(*tags, []string{"default"}) {
+		t.Fatal("expected [default] but got", *tags)
+	}
+	if err := f.Parse([]string{"--tag=a,b", "--tag=c"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !reflect.DeepEqual(*tags, []string{"a", "b", "c"}) {
+		t.Fatal("expected the default to be replaced with [a b c] but got", *tags)
+	}
+}
+
+func TestSliceValue(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.StringSlice("tag", []string{"x"}, "tags")
+	f.StringArray("arg", nil, "args")
+	f.IntSlice("port", []int{80}, "ports")
+	f.Int64Slice("size", nil, "sizes")
+	f.DurationSlice("timeout", nil, "timeouts")
+	f.EndpointSlice("endpoint", nil, "endpoints")
+	values := map[string][]string{
+		"tag":      {"a,b", "c"},
+		"arg":      {"a,b", "c"},
+		"port":     {"443", "8080"},
+		"size":     {"1", "-2"},
+		"timeout":  {"1s", "2m0s"},
+		"endpoint": {"a:1", "b:2"},
+	}
+	for name, elems := range values {
+		sv, ok := f.Lookup(name).Value.(SliceValue)
+		if !ok {
+			t.Errorf("%s: expected a SliceValue", name)
+			continue
+		}
+		if err := sv.Replace(elems[:1]); err != nil {
+			t.Errorf("%s: expected no error; got %v", name, err)
+		}
+		if err := sv.Append(elems[1]); err != nil {
+			t.Errorf("%s: expected no error; got %v", name, err)
+		}
+		if got := sv.GetSlice(); !reflect.DeepEqual(got, elems) {
+			t.Errorf("%s: expected %q but got %q", name, elems, got)
+		}
+	}
+	sv := f.Lookup("port").Value.(SliceValue)
+	if err := sv.Replace([]string{"1", "x"}); err == nil {
+		t.Error("expected an error replacing with an invalid element")
+	}
+	if got := sv.GetSlice(); !reflect.DeepEqual(got, []string{"443", "8080"}) {
+		t.Errorf("expected a failed Replace to leave %q but got %q", []string{"443", "8080"}, got)
+	}
+}
+
+func TestGetStringSlice(t *testing.T) {
+	var tags []string
+	f := setUpStringSliceFlagSet(&tags)
+	f.Int("count", 0, "")
+	if err := f.Parse([]string{"--tag=a,b"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	got, err := f.GetStringSlice("tag")
+	if err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatal("expected [a b] but got", got)
+	}
+	got[0] = "changed"
+	if tags[0] != "a" {
+		t.Error("expected the returned slice to be a copy; flag now holds", tags)
+	}
+	if _, err := f.GetStringSlice("count"); err == nil {
+		t.Error("expected an error getting an int flag as a string slice")
+	}
+	if _, err := f.GetStringSlice("missing"); err == nil {
+		t.Error("expected an error for an unknown flag")
+	}
+}
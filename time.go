@@ -0,0 +1,130 @@
+package pflag
+
+import (
+	"fmt"
+	"time"
+)
+
+// -- time.Time Value
+type timeValue struct {
+	value   *time.Time
+	layouts []string
+}
+
+func newTimeValue(layouts []string, val time.Time, p *time.Time) *timeValue {
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339}
+	}
+	*p = val
+	return &timeValue{value: p, layouts: layouts}
+}
+
+// Set parses s with each layout in turn and stores the first that succeeds.
+func (t *timeValue) Set(s string) error {
+	for _, layout := range t.layouts {
+		if v, err := time.Parse(layout, s); err == nil {
+			*t.value = v
+			return nil
+		}
+	}
+	return fmt.Errorf("time does not match any of the layouts %q", t.layouts)
+}
+
+// String formats the time with the first layout, or returns the empty
+// string for the zero time.
+func (t *timeValue) String() string {
+	if t.value.IsZero() {
+		return ""
+	}
+	return t.value.Format(t.layouts[0])
+}
+
+func (t *timeValue) Type() string { return "time" }
+
+func (t *timeValue) clone() Value {
+	v := *t.value
+	return &timeValue{value: &v, layouts: t.layouts}
+}
+
+func (t *timeValue) save() func() {
+	v := *t.value
+	return func() { *t.value = v }
+}
+
+func (t *timeValue) Get() interface{} { return *t.value }
+
+// reset is like Set, but also accepts the empty string for the zero time.
+func (t *timeValue) reset(s string) error {
+	if s == "" {
+		*t.value = time.Time{}
+		return nil
+	}
+	return t.Set(s)
+}
+
+// TimeVar defines a time.Time flag with specified name, accepted layouts, default value,
+// and usage string. Values are parsed with the first of layouts that matches, as by
+// time.Parse; if layouts is empty, time.RFC3339 is used.
+// The argument p points to a time.Time variable in which to store the value of the flag.
+func (f *FlagSet) TimeVar(p *time.Time, name string, layouts []string, value time.Time, usage string) {
+	f.VarP(newTimeValue(layouts, value, p), name, "", usage)
+}
+
+// Like TimeVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) TimeVarP(p *time.Time, name, shorthand string, layouts []string, value time.Time, usage string) {
+	f.VarP(newTimeValue(layouts, value, p), name, shorthand, usage)
+}
+
+// TimeVar defines a time.Time flag with specified name, accepted layouts, default value,
+// and usage string. Values are parsed with the first of layouts that matches, as by
+// time.Parse; if layouts is empty, time.RFC3339 is used.
+// The argument p points to a time.Time variable in which to store the value of the flag.
+func TimeVar(p *time.Time, name string, layouts []string, value time.Time, usage string) {
+	CommandLine.VarP(newTimeValue(layouts, value, p), name, "", usage)
+}
+
+// Like TimeVar, but accepts a shorthand letter that can be used after a single dash.
+func TimeVarP(p *time.Time, name, shorthand string, layouts []string, value time.Time, usage string) {
+	CommandLine.VarP(newTimeValue(layouts, value, p), name, shorthand, usage)
+}
+
+// Time defines a time.Time flag with specified name, accepted layouts, default value,
+// and usage string. The return value is the address of a time.Time variable that stores
+// the value of the flag.
+func (f *FlagSet) Time(name string, layouts []string, value time.Time, usage string) *time.Time {
+	p := new(time.Time)
+	f.TimeVarP(p, name, "", layouts, value, usage)
+	return p
+}
+
+// Like Time, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) TimeP(name, shorthand string, layouts []string, value time.Time, usage string) *time.Time {
+	p := new(time.Time)
+	f.TimeVarP(p, name, shorthand, layouts, value, usage)
+	return p
+}
+
+// Time defines a time.Time flag with specified name, accepted layouts, default value,
+// and usage string. The return value is the address of a time.Time variable that stores
+// the value of the flag.
+func Time(name string, layouts []string, value time.Time, usage string) *time.Time {
+	return CommandLine.TimeP(name, "", layouts, value, usage)
+}
+
+// Like Time, but accepts a shorthand letter that can be used after a single dash.
+func TimeP(name, shorthand string, layouts []string, value time.Time, usage string) *time.Time {
+	return CommandLine.TimeP(name, shorthand, layouts, value, usage)
+}
+
+// GetTime returns the time.Time value of the named flag.
+func (f *FlagSet) GetTime(name string) (time.Time, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	tv, ok := v.(*timeValue)
+	if !ok {
+		return time.Time{}, fmt.Errorf("flag -%v is not a time flag", name)
+	}
+	return *tv.value, nil
+}
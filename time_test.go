@@ -0,0 +1,62 @@
+package pflag
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTime(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	since := f.TimeP("since", "s", []string{time.RFC3339, "2006-01-02"}, time.Time{}, "start time")
+	if s := f.Lookup("since").DefValue; s != "" {
+		t.Fatalf("expected an empty default for the zero time but got %q", s)
+	}
+
+	if err := f.Parse([]string{"--since=2024-03-01T12:30:00+02:00"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	expected := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	if !since.Equal(expected) {
+		t.Fatalf("expected %v but got %v", expected, *since)
+	}
+
+	if err := f.Parse([]string{"-s", "2024-03-01"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !since.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatal("expected midnight on 2024-03-01 but got", *since)
+	}
+	if s := f.Lookup("since").Value.String(); s != "2024-03-01T00:00:00Z" {
+		t.Fatalf("expected the first layout to be used for String, got %q", s)
+	}
+	if v, err := f.GetTime("since"); err != nil || !v.Equal(*since) {
+		t.Fatalf("expected GetTime to return %v but got %v, %v", *since, v, err)
+	}
+}
+
+func TestTimeInvalid(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	f.Time("since", []string{"2006-01-02", "15:04"}, time.Time{}, "start time")
+	err := f.Parse([]string{"--since=yesterday"})
+	if err == nil || !strings.Contains(err.Error(), `["2006-01-02" "15:04"]`) {
+		t.Fatal("expected an error listing the layouts; got", err)
+	}
+}
+
+func TestTimeDefaultLayout(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	at := f.Time("at", nil, time.Time{}, "")
+	if err := f.Parse([]string{"--at=2024-01-02T03:04:05Z"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if at.Year() != 2024 {
+		t.Fatal("expected RFC 3339 to be accepted by default, got", *at)
+	}
+	if err := f.Parse([]string{"--at=2024-01-02"}); err == nil {
+		t.Fatal("expected an error for a date without RFC 3339 time")
+	}
+}
@@ -0,0 +1,86 @@
+package pflag
+
+import (
+	"fmt"
+	"time"
+)
+
+// TTL is a time-to-live: either a duration or infinite.
+type TTL struct {
+	Duration time.Duration // the duration, when not infinite
+	Infinite bool          // true if the TTL never expires
+}
+
+// IsInfinite reports whether the TTL never expires.
+func (t TTL) IsInfinite() bool { return t.Infinite }
+
+func (t TTL) String() string {
+	if t.Infinite {
+		return "never"
+	}
+	return t.Duration.String()
+}
+
+// -- TTL Value
+type ttlValue TTL
+
+func newTTLValue(val TTL, p *TTL) *ttlValue {
+	*p = val
+	return (*ttlValue)(p)
+}
+
+func (t *ttlValue) Set(s string) error {
+	switch s {
+	case "never", "forever", "infinite":
+		*t = ttlValue{Infinite: true}
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("expected a duration or one of never, forever, infinite: %q", s)
+	}
+	*t = ttlValue{Duration: v}
+	return nil
+}
+
+func (t *ttlValue) String() string { return TTL(*t).String() }
+
+func (t *ttlValue) Type() string { return "ttl" }
+
+func (t *ttlValue) clone() Value {
+	v := *t
+	return &v
+}
+
+func (t *ttlValue) save() func() {
+	v := *t
+	return func() { *t = v }
+}
+
+func (t *ttlValue) Get() interface{} { return TTL(*t) }
+
+// TTLVar defines a TTL flag with specified name, default value, and usage string.
+// The flag accepts any input valid for time.ParseDuration, or one of the
+// keywords never, forever or infinite for a TTL that does not expire.
+// The argument p points to a TTL variable in which to store the value of the flag.
+func (f *FlagSet) TTLVar(p *TTL, name string, value TTL, usage string) {
+	f.VarP(newTTLValue(value, p), name, "", usage)
+}
+
+// Like TTLVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) TTLVarP(p *TTL, name, shorthand string, value TTL, usage string) {
+	f.VarP(newTTLValue(value, p), name, shorthand, usage)
+}
+
+// TTLVar defines a TTL flag with specified name, default value, and usage string.
+// The flag accepts any input valid for time.ParseDuration, or one of the
+// keywords never, forever or infinite for a TTL that does not expire.
+// The argument p points to a TTL variable in which to store the value of the flag.
+func TTLVar(p *TTL, name string, value TTL, usage string) {
+	CommandLine.VarP(newTTLValue(value, p), name, "", usage)
+}
+
+// Like TTLVar, but accepts a shorthand letter that can be used after a single dash.
+func TTLVarP(p *TTL, name, shorthand string, value TTL, usage string) {
+	CommandLine.VarP(newTTLValue(value, p), name, shorthand, usage)
+}
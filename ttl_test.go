@@ -0,0 +1,53 @@
+package pflag
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func setUpTTLFlagSet(ttl *TTL) *FlagSet {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	f.TTLVar(ttl, "ttl", TTL{Duration: time.Hour}, "time to live")
+	return f
+}
+
+func TestTTLDuration(t *testing.T) {
+	var ttl TTL
+	f := setUpTTLFlagSet(&ttl)
+	if err := f.Parse([]string{"--ttl=30m"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if ttl.IsInfinite() || ttl.Duration != 30*time.Minute {
+		t.Fatal("expected 30m but got", ttl)
+	}
+	if s := f.Lookup("ttl").Value.String(); s != "30m0s" {
+		t.Fatalf("expected %q but got %q", "30m0s", s)
+	}
+}
+
+func TestTTLNever(t *testing.T) {
+	var ttl TTL
+	f := setUpTTLFlagSet(&ttl)
+	if err := f.Parse([]string{"--ttl=never"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if !ttl.IsInfinite() {
+		t.Fatal("expected an infinite TTL but got", ttl)
+	}
+	if s := f.Lookup("ttl").Value.String(); s != "never" {
+		t.Fatalf("expected %q but got %q", "never", s)
+	}
+}
+
+func TestTTLInvalid(t *testing.T) {
+	var ttl TTL
+	f := setUpTTLFlagSet(&ttl)
+	if err := f.Parse([]string{"--ttl=sometimes"}); err == nil {
+		t.Fatal("expected an error for an invalid keyword")
+	}
+	if ttl.IsInfinite() || ttl.Duration != time.Hour {
+		t.Fatal("expected the default to be unchanged but got", ttl)
+	}
+}
@@ -15,12 +15,29 @@ func newUintValue(val uint, p *uint) *uintValue {
 
 func (i *uintValue) Set(s string) error {
 	v, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return err
+	}
 	*i = uintValue(v)
-	return err
+	return nil
 }
 
 func (i *uintValue) String() string { return fmt.Sprintf("%v", *i) }
 
+func (i *uintValue) Type() string { return "uint" }
+
+func (i *uintValue) clone() Value {
+	v := *i
+	return &v
+}
+
+func (i *uintValue) save() func() {
+	v := *i
+	return func() { *i = v }
+}
+
+func (i *uintValue) Get() interface{} { return uint(*i) }
+
 // UintVar defines a uint flag with specified name, default value, and usage string.
 // The argument p points to a uint variable in which to store the value of the flag.
 func (f *FlagSet) UintVar(p *uint, name string, value uint, usage string) {
@@ -68,3 +85,35 @@ func Uint(name string, value uint, usage string) *uint {
 func UintP(name, shorthand string, value uint, usage string) *uint {
 	return CommandLine.UintP(name, shorthand, value, usage)
 }
+
+// GetUint returns the uint value of the named flag.
+func (f *FlagSet) GetUint(name string) (uint, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return 0, err
+	}
+	tv, ok := v.(*uintValue)
+	if !ok {
+		return 0, fmt.Errorf("flag -%v is not a uint flag", name)
+	}
+	return uint(*tv), nil
+}
+
+// SetUint sets the named uint flag to value and marks it as set, as Set
+// does, without formatting and parsing the value.
+func (f *FlagSet) SetUint(name string, value uint) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	tv, ok := flag.Value.(*uintValue)
+	if !ok {
+		return fmt.Errorf("flag -%v is not a uint flag", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	*tv = uintValue(value)
+	f.markSet(flag, SourceAPI)
+	return nil
+}
@@ -15,9 +15,24 @@ func newUint16Value(val uint16, p *uint16) *uint16Value {
 func (i *uint16Value) String() string { return fmt.Sprintf("%d", *i) }
 func (i *uint16Value) Set(s string) error {
 	v, err := strconv.ParseUint(s, 0, 16)
+	if err != nil {
+		return err
+	}
 	*i = uint16Value(v)
-	return err
+	return nil
 }
+func (i *uint16Value) Type() string { return "uint16" }
+
+func (i *uint16Value) clone() Value {
+	v := *i
+	return &v
+}
+
+func (i *uint16Value) save() func() {
+	v := *i
+	return func() { *i = v }
+}
+
 func (i *uint16Value) Get() interface{} {
 	return uint16(*i)
 }
@@ -69,3 +84,35 @@ func Uint16(name string, value uint16, usage string) *uint16 {
 func Uint16P(name, shorthand string, value uint16, usage string) *uint16 {
 	return CommandLine.Uint16P(name, shorthand, value, usage)
 }
+
+// GetUint16 returns the uint16 value of the named flag.
+func (f *FlagSet) GetUint16(name string) (uint16, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return 0, err
+	}
+	tv, ok := v.(*uint16Value)
+	if !ok {
+		return 0, fmt.Errorf("flag -%v is not a uint16 flag", name)
+	}
+	return uint16(*tv), nil
+}
+
+// SetUint16 sets the named uint16 flag to value and marks it as set, as Set
+// does, without formatting and parsing the value.
+func (f *FlagSet) SetUint16(name string, value uint16) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	tv, ok := flag.Value.(*uint16Value)
+	if !ok {
+		return fmt.Errorf("flag -%v is not a uint16 flag", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	*tv = uint16Value(value)
+	f.markSet(flag, SourceAPI)
+	return nil
+}
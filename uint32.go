@@ -15,9 +15,24 @@ func newUint32Value(val uint32, p *uint32) *uint32Value {
 func (i *uint32Value) String() string { return fmt.Sprintf("%d", *i) }
 func (i *uint32Value) Set(s string) error {
 	v, err := strconv.ParseUint(s, 0, 32)
+	if err != nil {
+		return err
+	}
 	*i = uint32Value(v)
-	return err
+	return nil
 }
+func (i *uint32Value) Type() string { return "uint32" }
+
+func (i *uint32Value) clone() Value {
+	v := *i
+	return &v
+}
+
+func (i *uint32Value) save() func() {
+	v := *i
+	return func() { *i = v }
+}
+
 func (i *uint32Value) Get() interface{} {
 	return uint32(*i)
 }
@@ -69,3 +84,35 @@ func Uint32(name string, value uint32, usage string) *uint32 {
 func Uint32P(name, shorthand string, value uint32, usage string) *uint32 {
 	return CommandLine.Uint32P(name, shorthand, value, usage)
 }
+
+// GetUint32 returns the uint32 value of the named flag.
+func (f *FlagSet) GetUint32(name string) (uint32, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return 0, err
+	}
+	tv, ok := v.(*uint32Value)
+	if !ok {
+		return 0, fmt.Errorf("flag -%v is not a uint32 flag", name)
+	}
+	return uint32(*tv), nil
+}
+
+// SetUint32 sets the named uint32 flag to value and marks it as set, as Set
+// does, without formatting and parsing the value.
+func (f *FlagSet) SetUint32(name string, value uint32) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	tv, ok := flag.Value.(*uint32Value)
+	if !ok {
+		return fmt.Errorf("flag -%v is not a uint32 flag", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	*tv = uint32Value(value)
+	f.markSet(flag, SourceAPI)
+	return nil
+}
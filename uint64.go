@@ -15,12 +15,29 @@ func newUint64Value(val uint64, p *uint64) *uint64Value {
 
 func (i *uint64Value) Set(s string) error {
 	v, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return err
+	}
 	*i = uint64Value(v)
-	return err
+	return nil
 }
 
 func (i *uint64Value) String() string { return fmt.Sprintf("%v", *i) }
 
+func (i *uint64Value) Type() string { return "uint64" }
+
+func (i *uint64Value) clone() Value {
+	v := *i
+	return &v
+}
+
+func (i *uint64Value) save() func() {
+	v := *i
+	return func() { *i = v }
+}
+
+func (i *uint64Value) Get() interface{} { return uint64(*i) }
+
 // Uint64Var defines a uint64 flag with specified name, default value, and usage string.
 // The argument p points to a uint64 variable in which to store the value of the flag.
 func (f *FlagSet) Uint64Var(p *uint64, name string, value uint64, usage string) {
@@ -68,3 +85,35 @@ func Uint64(name string, value uint64, usage string) *uint64 {
 func Uint64P(name, shorthand string, value uint64, usage string) *uint64 {
 	return CommandLine.Uint64P(name, shorthand, value, usage)
 }
+
+// GetUint64 returns the uint64 value of the named flag.
+func (f *FlagSet) GetUint64(name string) (uint64, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return 0, err
+	}
+	tv, ok := v.(*uint64Value)
+	if !ok {
+		return 0, fmt.Errorf("flag -%v is not a uint64 flag", name)
+	}
+	return uint64(*tv), nil
+}
+
+// SetUint64 sets the named uint64 flag to value and marks it as set, as Set
+// does, without formatting and parsing the value.
+func (f *FlagSet) SetUint64(name string, value uint64) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	tv, ok := flag.Value.(*uint64Value)
+	if !ok {
+		return fmt.Errorf("flag -%v is not a uint64 flag", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	*tv = uint64Value(value)
+	f.markSet(flag, SourceAPI)
+	return nil
+}
@@ -15,12 +15,29 @@ func newUint8Value(val uint8, p *uint8) *uint8Value {
 
 func (i *uint8Value) Set(s string) error {
 	v, err := strconv.ParseUint(s, 0, 8)
+	if err != nil {
+		return err
+	}
 	*i = uint8Value(v)
-	return err
+	return nil
 }
 
 func (i *uint8Value) String() string { return fmt.Sprintf("%v", *i) }
 
+func (i *uint8Value) Type() string { return "uint8" }
+
+func (i *uint8Value) clone() Value {
+	v := *i
+	return &v
+}
+
+func (i *uint8Value) save() func() {
+	v := *i
+	return func() { *i = v }
+}
+
+func (i *uint8Value) Get() interface{} { return uint8(*i) }
+
 // Uint8Var defines a uint8 flag with specified name, default value, and usage string.
 // The argument p points to a uint8 variable in which to store the value of the flag.
 func (f *FlagSet) Uint8Var(p *uint8, name string, value uint8, usage string) {
@@ -68,3 +85,35 @@ func Uint8(name string, value uint8, usage string) *uint8 {
 func Uint8P(name, shorthand string, value uint8, usage string) *uint8 {
 	return CommandLine.Uint8P(name, shorthand, value, usage)
 }
+
+// GetUint8 returns the uint8 value of the named flag.
+func (f *FlagSet) GetUint8(name string) (uint8, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return 0, err
+	}
+	tv, ok := v.(*uint8Value)
+	if !ok {
+		return 0, fmt.Errorf("flag -%v is not a uint8 flag", name)
+	}
+	return uint8(*tv), nil
+}
+
+// SetUint8 sets the named uint8 flag to value and marks it as set, as Set
+// does, without formatting and parsing the value.
+func (f *FlagSet) SetUint8(name string, value uint8) error {
+	flag, ok := f.lookup(name)
+	if !ok {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	tv, ok := flag.Value.(*uint8Value)
+	if !ok {
+		return fmt.Errorf("flag -%v is not a uint8 flag", name)
+	}
+	if err := f.checkMutable(flag); err != nil {
+		return err
+	}
+	*tv = uint8Value(value)
+	f.markSet(flag, SourceAPI)
+	return nil
+}
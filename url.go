@@ -0,0 +1,135 @@
+package pflag
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// -- *url.URL Value
+type urlValue struct {
+	value  **url.URL
+	strict bool // require a scheme and host; see MarkStrictURL
+}
+
+func newURLValue(val *url.URL, p **url.URL) *urlValue {
+	*p = val
+	return &urlValue{value: p}
+}
+
+func (u *urlValue) Set(s string) error {
+	v, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if u.strict && (v.Scheme == "" || v.Host == "") {
+		return fmt.Errorf("%q is not an absolute URL with a scheme and host", s)
+	}
+	*u.value = v
+	return nil
+}
+
+func (u *urlValue) String() string {
+	if *u.value == nil {
+		return ""
+	}
+	return (*u.value).String()
+}
+
+func (u *urlValue) Type() string { return "url" }
+
+func (u *urlValue) clone() Value {
+	v := *u.value
+	return &urlValue{value: &v, strict: u.strict}
+}
+
+func (u *urlValue) save() func() {
+	v := *u.value
+	return func() { *u.value = v }
+}
+
+func (u *urlValue) Get() interface{} { return *u.value }
+
+// URLVar defines a *url.URL flag with specified name, default value, and usage string.
+// Values are parsed with url.Parse, so relative URLs are accepted unless the flag is
+// marked with MarkStrictURL. The argument p points to a *url.URL variable in which to
+// store the value of the flag.
+func (f *FlagSet) URLVar(p **url.URL, name string, value *url.URL, usage string) {
+	f.VarP(newURLValue(value, p), name, "", usage)
+}
+
+// Like URLVar, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) URLVarP(p **url.URL, name, shorthand string, value *url.URL, usage string) {
+	f.VarP(newURLValue(value, p), name, shorthand, usage)
+}
+
+// URLVar defines a *url.URL flag with specified name, default value, and usage string.
+// Values are parsed with url.Parse, so relative URLs are accepted unless the flag is
+// marked with MarkStrictURL. The argument p points to a *url.URL variable in which to
+// store the value of the flag.
+func URLVar(p **url.URL, name string, value *url.URL, usage string) {
+	CommandLine.VarP(newURLValue(value, p), name, "", usage)
+}
+
+// Like URLVar, but accepts a shorthand letter that can be used after a single dash.
+func URLVarP(p **url.URL, name, shorthand string, value *url.URL, usage string) {
+	CommandLine.VarP(newURLValue(value, p), name, shorthand, usage)
+}
+
+// URL defines a *url.URL flag with specified name, default value, and usage string.
+// The return value is the address of a *url.URL variable that stores the value of the flag.
+func (f *FlagSet) URL(name string, value *url.URL, usage string) **url.URL {
+	p := new(*url.URL)
+	f.URLVarP(p, name, "", value, usage)
+	return p
+}
+
+// Like URL, but accepts a shorthand letter that can be used after a single dash.
+func (f *FlagSet) URLP(name, shorthand string, value *url.URL, usage string) **url.URL {
+	p := new(*url.URL)
+	f.URLVarP(p, name, shorthand, value, usage)
+	return p
+}
+
+// URL defines a *url.URL flag with specified name, default value, and usage string.
+// The return value is the address of a *url.URL variable that stores the value of the flag.
+func URL(name string, value *url.URL, usage string) **url.URL {
+	return CommandLine.URLP(name, "", value, usage)
+}
+
+// Like URL, but accepts a shorthand letter that can be used after a single dash.
+func URLP(name, shorthand string, value *url.URL, usage string) **url.URL {
+	return CommandLine.URLP(name, shorthand, value, usage)
+}
+
+// MarkStrictURL makes the named URL flag reject values without both a
+// scheme and a host, such as relative paths.
+func (f *FlagSet) MarkStrictURL(name string) error {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return err
+	}
+	uv, ok := v.(*urlValue)
+	if !ok {
+		return fmt.Errorf("flag -%v is not a URL flag", name)
+	}
+	uv.strict = true
+	return nil
+}
+
+// MarkStrictURL makes the named command-line URL flag reject values without a scheme and host.
+func MarkStrictURL(name string) error {
+	return CommandLine.MarkStrictURL(name)
+}
+
+// GetURL returns the *url.URL value of the named flag.
+func (f *FlagSet) GetURL(name string) (*url.URL, error) {
+	v, err := f.flagValue(name)
+	if err != nil {
+		return nil, err
+	}
+	uv, ok := v.(*urlValue)
+	if !ok {
+		return nil, fmt.Errorf("flag -%v is not a URL flag", name)
+	}
+	return *uv.value, nil
+}
@@ -0,0 +1,60 @@
+package pflag
+
+import (
+	"io/ioutil"
+	"net/url"
+	"testing"
+)
+
+func TestURL(t *testing.T) {
+	def, _ := url.Parse("http://localhost:8080")
+	f := NewFlagSet("test", ContinueOnError)
+	endpoint := f.URLP("endpoint", "e", def, "endpoint")
+	if s := f.Lookup("endpoint").DefValue; s != "http://localhost:8080" {
+		t.Fatalf("expected default %q but got %q", "http://localhost:8080", s)
+	}
+
+	if err := f.Parse([]string{"--endpoint=https://example.com/api?v=2"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if u := *endpoint; u.Scheme != "https" || u.Host != "example.com" || u.Path != "/api" {
+		t.Fatal("expected https://example.com/api but got", u)
+	}
+	if s := f.Lookup("endpoint").Value.String(); s != "https://example.com/api?v=2" {
+		t.Fatalf("expected %q but got %q", "https://example.com/api?v=2", s)
+	}
+
+	if err := f.Parse([]string{"-e", "api/v1"}); err != nil {
+		t.Fatal("expected a relative URL to be accepted; got", err)
+	}
+	if u, err := f.GetURL("endpoint"); err != nil || u.Path != "api/v1" || u.IsAbs() {
+		t.Fatalf("expected GetURL to return the relative URL but got %v, %v", u, err)
+	}
+}
+
+func TestURLInvalid(t *testing.T) {
+	f := NewFlagSet("test", ContinueOnError)
+	f.SetOutput(ioutil.Discard)
+	endpoint := f.URL("endpoint", nil, "endpoint")
+	if err := f.Parse([]string{"--endpoint=http://[::1"}); err == nil {
+		t.Fatal("expected an error for a malformed URL")
+	}
+	if *endpoint != nil {
+		t.Fatal("expected the value to be unchanged but got", *endpoint)
+	}
+
+	if err := f.MarkStrictURL("endpoint"); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+	if err := f.Parse([]string{"--endpoint=api/v1"}); err == nil {
+		t.Fatal("expected a strict URL flag to reject a relative URL")
+	}
+	if err := f.Parse([]string{"--endpoint=https://example.com"}); err != nil {
+		t.Fatal("expected no error; got", err)
+	}
+
+	f.String("name", "", "")
+	if err := f.MarkStrictURL("name"); err == nil {
+		t.Fatal("expected an error marking a string flag")
+	}
+}